@@ -0,0 +1,45 @@
+package config
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	ContainerRectangle = "rectangle"
+	ContainerCircle    = "circle"
+)
+
+const (
+	MotionSinusoid  = "sinusoid"
+	MotionRotation  = "rotation"
+	MotionKeyframes = "keyframes"
+)
+
+type Keyframe struct {
+	Time   float32    `mapstructure:"time" toml:"time"`
+	Offset rl.Vector2 `mapstructure:"offset" toml:"offset"`
+	Angle  float32    `mapstructure:"angle" toml:"angle"`
+}
+
+// Angles are taken around Pivot.
+type Motion struct {
+	Type             string     `mapstructure:"type" toml:"type"`
+	Pivot            rl.Vector2 `mapstructure:"pivot" toml:"pivot"`
+	Amplitude        rl.Vector2 `mapstructure:"amplitude" toml:"amplitude,omitempty"`
+	AngularAmplitude float32    `mapstructure:"angular_amplitude" toml:"angular_amplitude,omitempty"`
+	Frequency        float32    `mapstructure:"frequency" toml:"frequency,omitempty"`
+	Phase            float32    `mapstructure:"phase" toml:"phase,omitempty"`
+	AngularVelocity  float32    `mapstructure:"angular_velocity" toml:"angular_velocity,omitempty"`
+	Keyframes        []Keyframe `mapstructure:"keyframes" toml:"keyframes,omitempty"`
+	Loop             bool       `mapstructure:"loop" toml:"loop,omitempty"`
+}
+
+type Boundary struct {
+	Shape       string       `mapstructure:"shape"`
+	Points      []rl.Vector2 `mapstructure:"points"`
+	Center      rl.Vector2   `mapstructure:"center"`
+	Radius      float32      `mapstructure:"radius"`
+	Thickness   float32      `mapstructure:"thickness"`
+	Restitution *float32     `mapstructure:"restitution"`
+	Motion      Motion       `mapstructure:"motion"`
+}
@@ -13,21 +13,23 @@ type Config struct {
 	SidebarWidth            int32
 	ViewportX               int32
 	ViewportY               int32
-	GameX                   int32
-	GameY                   int32
-	GameZ                   int32
+	GameX                   float32
+	GameY                   float32
+	GameZ                   float32
 	TargetFPS               int32
 	IsResizable             bool
 	ParticleNumber          int32
 	ParticleRadius          float32
 	ParticleMass            float32
 	ParticleInitialSpacing  float32
+	InitialSpeedMax         float32
 	ShowVectors             bool
 	ScaleFactor             float32
 	ParticleElasticity      float32
 	WallElasticity          float32
 	ApplyGravity            bool
-	Gravity                 float32
+	Gravity                 rl.Vector2
+	GravityZ                float32
 	ShowQuadtree            bool
 	ShowTrail               bool
 	ShouldBeProfiled        bool
@@ -44,6 +46,81 @@ type Config struct {
 	ShowOverlay             bool
 	SetRandomColor          bool
 	ShowSpeedColor          bool
+	SceneFile               string
+	ExportFile              string
+	ObstacleThickness       float32
+	MovingBoundaries        []Boundary
+	ContainerShape          string
+	OpenTop                 bool
+	OpenFloor               bool
+	PeriodicX               bool
+	PeriodicY               bool
+	Mode3D                  bool
+	SpringStiffness         float32
+	SpringDamping           float32
+	RigidConstraints        bool
+	ConstraintBreakStrain   float32
+	SoftBodyParticles       int32
+	SoftBodyPressure        float32
+	UnitFriction            float32
+	WallFriction            float32
+	ApplyLinearDrag         bool
+	LinearDrag              float32
+	ApplyAirDrag            bool
+	AirDrag                 float32
+	ApplyXSPH               bool
+	XSPHSmoothing           float32
+	ApplyCohesion           bool
+	CohesionStrength        float32
+	CohesionRange           float32
+	ShowCohesion            bool
+	Materials               []Material
+	MaterialInteractions    []MaterialInteraction
+	ActiveMaterial          string
+	ApplyHeat               bool
+	AmbientTemperature      float32
+	SpecificHeat            float32
+	Conductivity            float32
+	WallConductivity        float32
+	FloorTemperature        float32
+	CeilingTemperature      float32
+	ApplyBuoyancy           bool
+	ThermalExpansion        float32
+	ShowTemperature         bool
+	FieldStrength           float32
+	FieldLinearFalloff      bool
+	InteractionRadius       float32
+	GrabStiffness           float32
+	PushStrength            float32
+	StirStrength            float32
+	ApplySelfGravity        bool
+	GravitationalConstant   float32
+	BarnesHutTheta          float32
+	GravitySoftening        float32
+	InteractionMode         string
+	LJEpsilon               float32
+	LJSigma                 float32
+	LJCutoff                float32
+	Thermostat              string
+	TargetTemperature       float32
+	BerendsenTau            float32
+	Integrator              string
+	ComparisonDuration      float32
+	ApplyCCD                bool
+	CCDThreshold            float32
+	SolverIterations        int
+	SolverOrdering          string
+	OverRelaxation          float32
+	ApplySleep              bool
+	SleepSpeed              float32
+	SleepAcceleration       float32
+	SleepDelay              float32
+	ShowSleeping            bool
+	AdaptiveTimestep        bool
+	CourantNumber           float32
+	MinTimestep             float32
+	MaxTimestep             float32
+	MaxSubsteps             int
 }
 
 func ReadConfig(filepath string) (*Config, error) {
@@ -58,21 +135,22 @@ func ReadConfig(filepath string) (*Config, error) {
 		FullScreen:              viper.GetBool("full_screen"),
 		WindowWidth:             viper.GetInt32("window_width"),
 		WindowHeight:            viper.GetInt32("window_height"),
-		GameX:                   viper.GetInt32("game_x"),
-		GameY:                   viper.GetInt32("game_y"),
-		GameZ:                   viper.GetInt32("game_z"),
+		GameX:                   float32(viper.GetFloat64("game_x")),
+		GameY:                   float32(viper.GetFloat64("game_y")),
+		GameZ:                   float32(viper.GetFloat64("game_z")),
 		TargetFPS:               viper.GetInt32("target_fps"),
 		IsResizable:             viper.GetBool("is_resizable"),
 		ParticleNumber:          viper.GetInt32("particle_number"),
 		ParticleRadius:          float32(viper.GetFloat64("particle_radius")),
 		ParticleMass:            float32(viper.GetFloat64("particle_mass")),
 		ParticleInitialSpacing:  float32(viper.GetFloat64("particle_initial_spacing")),
+		InitialSpeedMax:         float32(viper.GetFloat64("initial_speed_max")),
 		ShowVectors:             viper.GetBool("show_vectors"),
 		ScaleFactor:             float32(viper.GetFloat64("scale_factor")),
 		ParticleElasticity:      float32(viper.GetFloat64("particle_elasticity")),
 		WallElasticity:          float32(viper.GetFloat64("wall_elasticity")),
 		ApplyGravity:            viper.GetBool("apply_gravity"),
-		Gravity:                 float32(viper.GetFloat64("gravity")),
+		GravityZ:                float32(viper.GetFloat64("gravity.z")),
 		ShowQuadtree:            viper.GetBool("show_quadtree"),
 		ShowTrail:               viper.GetBool("show_trail"),
 		ShouldBeProfiled:        viper.GetBool("should_be_profiled"),
@@ -89,6 +167,104 @@ func ReadConfig(filepath string) (*Config, error) {
 		ShowOverlay:             viper.GetBool("show_overlay"),
 		SetRandomColor:          viper.GetBool("set_random_color"),
 		ShowSpeedColor:          viper.GetBool("show_speed_color"),
+		SceneFile:               viper.GetString("scene_file"),
+		ExportFile:              viper.GetString("export_file"),
+		ObstacleThickness:       float32(viper.GetFloat64("obstacle_thickness")),
+		ContainerShape:          viper.GetString("container_shape"),
+		OpenTop:                 viper.GetBool("open_top"),
+		OpenFloor:               viper.GetBool("open_floor"),
+		PeriodicX:               viper.GetBool("periodic_x"),
+		PeriodicY:               viper.GetBool("periodic_y"),
+		Mode3D:                  viper.GetBool("mode_3d"),
+		SpringStiffness:         float32(viper.GetFloat64("spring_stiffness")),
+		SpringDamping:           float32(viper.GetFloat64("spring_damping")),
+		RigidConstraints:        viper.GetBool("rigid_constraints"),
+		ConstraintBreakStrain:   float32(viper.GetFloat64("constraint_break_strain")),
+		SoftBodyParticles:       viper.GetInt32("soft_body_particles"),
+		SoftBodyPressure:        float32(viper.GetFloat64("soft_body_pressure")),
+		UnitFriction:            float32(viper.GetFloat64("unit_friction")),
+		WallFriction:            float32(viper.GetFloat64("wall_friction")),
+		ApplyLinearDrag:         viper.GetBool("apply_linear_drag"),
+		LinearDrag:              float32(viper.GetFloat64("linear_drag")),
+		ApplyAirDrag:            viper.GetBool("apply_air_drag"),
+		AirDrag:                 float32(viper.GetFloat64("air_drag")),
+		ApplyXSPH:               viper.GetBool("apply_xsph"),
+		XSPHSmoothing:           float32(viper.GetFloat64("xsph_smoothing")),
+		ApplyCohesion:           viper.GetBool("apply_cohesion"),
+		CohesionStrength:        float32(viper.GetFloat64("cohesion_strength")),
+		CohesionRange:           float32(viper.GetFloat64("cohesion_range")),
+		ShowCohesion:            viper.GetBool("show_cohesion"),
+		ActiveMaterial:          viper.GetString("active_material"),
+		ApplyHeat:               viper.GetBool("apply_heat"),
+		AmbientTemperature:      float32(viper.GetFloat64("ambient_temperature")),
+		SpecificHeat:            float32(viper.GetFloat64("specific_heat")),
+		Conductivity:            float32(viper.GetFloat64("conductivity")),
+		WallConductivity:        float32(viper.GetFloat64("wall_conductivity")),
+		FloorTemperature:        float32(viper.GetFloat64("floor_temperature")),
+		CeilingTemperature:      float32(viper.GetFloat64("ceiling_temperature")),
+		ApplyBuoyancy:           viper.GetBool("apply_buoyancy"),
+		ThermalExpansion:        float32(viper.GetFloat64("thermal_expansion")),
+		ShowTemperature:         viper.GetBool("show_temperature"),
+		FieldStrength:           float32(viper.GetFloat64("field_strength")),
+		FieldLinearFalloff:      viper.GetBool("field_linear_falloff"),
+		InteractionRadius:       float32(viper.GetFloat64("interaction_radius")),
+		GrabStiffness:           float32(viper.GetFloat64("grab_stiffness")),
+		PushStrength:            float32(viper.GetFloat64("push_strength")),
+		StirStrength:            float32(viper.GetFloat64("stir_strength")),
+		ApplySelfGravity:        viper.GetBool("apply_self_gravity"),
+		GravitationalConstant:   float32(viper.GetFloat64("gravitational_constant")),
+		BarnesHutTheta:          float32(viper.GetFloat64("barnes_hut_theta")),
+		GravitySoftening:        float32(viper.GetFloat64("gravity_softening")),
+		InteractionMode:         viper.GetString("interaction_mode"),
+		LJEpsilon:               float32(viper.GetFloat64("lj_epsilon")),
+		LJSigma:                 float32(viper.GetFloat64("lj_sigma")),
+		LJCutoff:                float32(viper.GetFloat64("lj_cutoff")),
+		Thermostat:              viper.GetString("thermostat"),
+		TargetTemperature:       float32(viper.GetFloat64("target_temperature")),
+		BerendsenTau:            float32(viper.GetFloat64("berendsen_tau")),
+		Integrator:              viper.GetString("integrator"),
+		ComparisonDuration:      float32(viper.GetFloat64("comparison_duration")),
+		ApplyCCD:                viper.GetBool("apply_ccd"),
+		CCDThreshold:            float32(viper.GetFloat64("ccd_threshold")),
+		SolverIterations:        viper.GetInt("solver_iterations"),
+		SolverOrdering:          viper.GetString("solver_ordering"),
+		OverRelaxation:          float32(viper.GetFloat64("over_relaxation")),
+		ApplySleep:              viper.GetBool("apply_sleep"),
+		SleepSpeed:              float32(viper.GetFloat64("sleep_speed")),
+		SleepAcceleration:       float32(viper.GetFloat64("sleep_acceleration")),
+		SleepDelay:              float32(viper.GetFloat64("sleep_delay")),
+		ShowSleeping:            viper.GetBool("show_sleeping"),
+		AdaptiveTimestep:        viper.GetBool("adaptive_timestep"),
+		CourantNumber:           float32(viper.GetFloat64("courant_number")),
+		MinTimestep:             float32(viper.GetFloat64("min_timestep")),
+		MaxTimestep:             float32(viper.GetFloat64("max_timestep")),
+		MaxSubsteps:             viper.GetInt("max_substeps"),
+	}
+
+	gravity, err := readGravity()
+	if err != nil {
+		return nil, err
+	}
+	config.Gravity = gravity
+
+	if err := viper.UnmarshalKey("moving_boundaries", &config.MovingBoundaries); err != nil {
+		return nil, err
+	}
+
+	if err := viper.UnmarshalKey("materials", &config.Materials); err != nil {
+		return nil, err
+	}
+
+	if err := viper.UnmarshalKey("material_interactions", &config.MaterialInteractions); err != nil {
+		return nil, err
+	}
+
+	if err := config.readMaterials(); err != nil {
+		return nil, err
+	}
+
+	if err := config.readIntegrator(); err != nil {
+		return nil, err
 	}
 
 	return config, nil
@@ -106,9 +282,6 @@ func (c *Config) UpdateWindowSettings() {
 	c.ViewportX = currentWidth - c.SidebarWidth
 	c.ViewportY = currentHeight
 
-	c.GameX = c.ViewportX
-	c.GameY = c.ViewportY
-
 	if c.FullScreen {
 		rl.ToggleFullscreen()
 	}
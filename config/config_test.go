@@ -1 +1,73 @@
 package config
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/spf13/viper"
+)
+
+func TestMaterialsAndInteractions(t *testing.T) {
+	restitution := float32(0.2)
+	c := &Config{
+		Materials: []Material{
+			{Name: "sand", Color: "#c2b280"},
+			{Name: "rubber", Color: "#222222"},
+		},
+		MaterialInteractions: []MaterialInteraction{
+			{A: "sand", B: "rubber", Restitution: &restitution},
+		},
+		ActiveMaterial: "sand",
+	}
+
+	if err := c.readMaterials(); err != nil {
+		t.Fatal(err)
+	}
+	if rgba := c.Material("sand").RGBA; rgba != (color.RGBA{0xc2, 0xb2, 0x80, 0xff}) {
+		t.Errorf("sand parsed as %v", rgba)
+	}
+	if c.Material("water") != nil {
+		t.Errorf("found a material that was never defined")
+	}
+	if interaction := c.Interaction("rubber", "sand"); interaction == nil || *interaction.Restitution != restitution {
+		t.Errorf("interaction looked up in reverse order as %v", interaction)
+	}
+	if c.Interaction("sand", "sand") != nil {
+		t.Errorf("found an interaction that was never defined")
+	}
+
+	c.MaterialInteractions = append(c.MaterialInteractions, MaterialInteraction{A: "sand", B: "water"})
+	if err := c.readMaterials(); err == nil {
+		t.Errorf("interaction with an unknown material accepted")
+	}
+}
+
+func TestGravityVector(t *testing.T) {
+	defer viper.Reset()
+
+	viper.Set("gravity", 9.81)
+	if gravity, err := readGravity(); err != nil || gravity != (rl.Vector2{Y: 9.81}) {
+		t.Errorf("gravity magnitude read as %v, %v", gravity, err)
+	}
+
+	viper.Set("gravity", map[string]interface{}{"x": 1.5, "y": -2})
+	if gravity, err := readGravity(); err != nil || gravity != (rl.Vector2{X: 1.5, Y: -2}) {
+		t.Errorf("gravity vector read as %v, %v", gravity, err)
+	}
+
+	c := &Config{}
+	c.SetGravity(10, math.Pi/2)
+	if math.Abs(float64(c.Gravity.X+10)) > 1e-5 || math.Abs(float64(c.Gravity.Y)) > 1e-5 {
+		t.Errorf("gravity tilted a quarter turn is %v, want 10 m/s² to the left", c.Gravity)
+	}
+	if math.Abs(float64(c.GravityAngle()-math.Pi/2)) > 1e-5 || math.Abs(float64(c.GravityMagnitude()-10)) > 1e-5 {
+		t.Errorf("read back as %v m/s² at %v rad", c.GravityMagnitude(), c.GravityAngle())
+	}
+
+	c.Gravity = rl.Vector2{Y: 9.81}
+	if gravity := c.Gravity3D(); gravity.Y != -9.81 {
+		t.Errorf("3D gravity %v, want it pointing down the Y axis", gravity)
+	}
+}
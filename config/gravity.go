@@ -0,0 +1,39 @@
+package config
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/spf13/viper"
+)
+
+// Older configs give gravity as a magnitude pointing down the screen.
+func readGravity() (rl.Vector2, error) {
+	if _, ok := viper.Get("gravity").(map[string]interface{}); ok {
+		gravity := rl.Vector2{}
+		err := viper.UnmarshalKey("gravity", &gravity)
+		return gravity, err
+	}
+	return rl.Vector2{X: 0, Y: float32(viper.GetFloat64("gravity"))}, nil
+}
+
+// Y points up in 3D, so the y component is flipped.
+func (c *Config) Gravity3D() rl.Vector3 {
+	return rl.Vector3{X: c.Gravity.X, Y: -c.Gravity.Y, Z: c.GravityZ}
+}
+
+func (c *Config) GravityMagnitude() float32 {
+	return rl.Vector2Length(c.Gravity)
+}
+
+// GravityAngle is in radians from straight down, positive towards the left.
+func (c *Config) GravityAngle() float32 {
+	return float32(math.Atan2(float64(-c.Gravity.X), float64(c.Gravity.Y)))
+}
+
+func (c *Config) SetGravity(magnitude, angle float32) {
+	c.Gravity = rl.Vector2{
+		X: -magnitude * float32(math.Sin(float64(angle))),
+		Y: magnitude * float32(math.Cos(float64(angle))),
+	}
+}
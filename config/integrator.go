@@ -0,0 +1,26 @@
+package config
+
+import "fmt"
+
+const (
+	IntegratorVerlet         = "verlet"
+	IntegratorVelocityVerlet = "velocity_verlet"
+	IntegratorRK4            = "rk4"
+
+	// IntegratorEuler is an alias of position Verlet, which is the same update as
+	// semi-implicit Euler.
+	IntegratorEuler = "euler"
+)
+
+var Integrators = []string{IntegratorVerlet, IntegratorVelocityVerlet, IntegratorRK4}
+
+func (c *Config) readIntegrator() error {
+	switch c.Integrator {
+	case "", IntegratorEuler:
+		c.Integrator = IntegratorVerlet
+	case IntegratorVerlet, IntegratorVelocityVerlet, IntegratorRK4:
+	default:
+		return fmt.Errorf("unknown integrator %q", c.Integrator)
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package config
+
+const (
+	InteractionHardDisc     = "hard_disc"
+	InteractionLennardJones = "lennard_jones"
+)
+
+const (
+	ThermostatNone      = "none"
+	ThermostatRescale   = "rescale"
+	ThermostatBerendsen = "berendsen"
+)
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/alexanderi96/go-fluid-simulator/utils"
+)
+
+type Material struct {
+	Name       string  `mapstructure:"name"`
+	Radius     float32 `mapstructure:"radius"`
+	Mass       float32 `mapstructure:"mass"`
+	Elasticity float32 `mapstructure:"elasticity"`
+	Color      string  `mapstructure:"color"`
+	Friction   float32 `mapstructure:"friction"`
+	Cohesion   float32 `mapstructure:"cohesion"`
+
+	RGBA color.RGBA `mapstructure:"-"`
+}
+
+// Unset values fall back to the mean of the two materials.
+type MaterialInteraction struct {
+	A           string   `mapstructure:"a"`
+	B           string   `mapstructure:"b"`
+	Restitution *float32 `mapstructure:"restitution"`
+	Cohesion    *float32 `mapstructure:"cohesion"`
+}
+
+func (c *Config) Material(name string) *Material {
+	for i := range c.Materials {
+		if c.Materials[i].Name == name {
+			return &c.Materials[i]
+		}
+	}
+	return nil
+}
+
+func (c *Config) Interaction(a, b string) *MaterialInteraction {
+	for i := range c.MaterialInteractions {
+		interaction := &c.MaterialInteractions[i]
+		if (interaction.A == a && interaction.B == b) || (interaction.A == b && interaction.B == a) {
+			return interaction
+		}
+	}
+	return nil
+}
+
+func (c *Config) readMaterials() error {
+	for i := range c.Materials {
+		material := &c.Materials[i]
+
+		rgba, err := utils.ParseHexColor(material.Color)
+		if err != nil {
+			return fmt.Errorf("material %s: %v", material.Name, err)
+		}
+		material.RGBA = rgba
+	}
+
+	for _, interaction := range c.MaterialInteractions {
+		if c.Material(interaction.A) == nil || c.Material(interaction.B) == nil {
+			return fmt.Errorf("material interaction %s/%s: unknown material", interaction.A, interaction.B)
+		}
+	}
+
+	if c.ActiveMaterial != "" && c.Material(c.ActiveMaterial) == nil {
+		return fmt.Errorf("active material %s is not defined", c.ActiveMaterial)
+	}
+
+	return nil
+}
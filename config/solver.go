@@ -0,0 +1,6 @@
+package config
+
+const (
+	SolverGaussSeidel = "gauss_seidel"
+	SolverJacobi      = "jacobi"
+)
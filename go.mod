@@ -6,6 +6,7 @@ require (
 	github.com/gen2brain/raylib-go/raygui v0.0.0-20231017191853-10c61020bc6c
 	github.com/gen2brain/raylib-go/raylib v0.0.0-20231010155130-e9da61431c85
 	github.com/google/uuid v1.3.1
+	github.com/pelletier/go-toml/v2 v2.1.0
 	github.com/spf13/viper v1.17.0
 )
 
@@ -14,7 +15,6 @@ require (
 	github.com/hashicorp/hcl v1.0.0 // indirect
 	github.com/magiconair/properties v1.8.7 // indirect
 	github.com/mitchellh/mapstructure v1.5.0 // indirect
-	github.com/pelletier/go-toml/v2 v2.1.0 // indirect
 	github.com/sagikazarmark/locafero v0.3.0 // indirect
 	github.com/sagikazarmark/slog-shim v0.1.0 // indirect
 	github.com/sourcegraph/conc v0.3.0 // indirect
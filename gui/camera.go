@@ -0,0 +1,68 @@
+package gui
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-fluid-simulator/physics"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	minZoom        = 0.05
+	maxZoom        = 20
+	zoomPerWheel   = 0.1
+	fitWorldMargin = 0.95
+)
+
+var camera = rl.Camera2D{}
+
+func fitCameraToWorld(s *physics.Simulation) {
+	zoom := math.Min(
+		float64(s.Config.ViewportX)/float64(toPixels(s.Config.GameX)),
+		float64(s.Config.ViewportY)/float64(toPixels(s.Config.GameY)),
+	)
+
+	camera.Zoom = float32(zoom) * fitWorldMargin
+	camera.Rotation = 0
+	camera.Target = toPixelsV(rl.Vector2{X: s.Config.GameX / 2, Y: s.Config.GameY / 2})
+}
+
+func handleCamera(s *physics.Simulation) {
+	syncScale(s)
+
+	if camera.Zoom == 0 {
+		fitCameraToWorld(s)
+	}
+
+	camera.Offset = rl.Vector2{X: float32(s.Config.ViewportX) / 2, Y: float32(s.Config.ViewportY) / 2}
+
+	if rl.IsKeyPressed(rl.KeyF) {
+		fitCameraToWorld(s)
+	}
+
+	if !isMouseInViewport(s) {
+		return
+	}
+
+	if rl.IsMouseButtonDown(rl.MouseMiddleButton) {
+		delta := rl.GetMouseDelta()
+		camera.Target = rl.Vector2Subtract(camera.Target, rl.Vector2Scale(delta, 1/camera.Zoom))
+	}
+
+	if wheel := rl.GetMouseWheelMove(); wheel != 0 {
+		before := rl.GetScreenToWorld2D(rl.GetMousePosition(), camera)
+		camera.Zoom = rl.Clamp(camera.Zoom*(1+wheel*zoomPerWheel), minZoom, maxZoom)
+		after := rl.GetScreenToWorld2D(rl.GetMousePosition(), camera)
+		camera.Target = rl.Vector2Add(camera.Target, rl.Vector2Subtract(before, after))
+	}
+}
+
+func worldMousePosition() rl.Vector2 {
+	return toMetresV(rl.GetScreenToWorld2D(rl.GetMousePosition(), camera))
+}
+
+func isMouseInViewport(s *physics.Simulation) bool {
+	position := rl.GetMousePosition()
+	return position.X > 0 && position.X < float32(s.Config.ViewportX) && position.Y > 0 && position.Y < float32(s.Config.ViewportY)
+}
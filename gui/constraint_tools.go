@@ -0,0 +1,134 @@
+package gui
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-fluid-simulator/physics"
+	"github.com/alexanderi96/go-fluid-simulator/utils"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type ConstraintTool int32
+
+const (
+	ConstraintNone ConstraintTool = iota
+	ConstraintToolLink
+	ConstraintToolChain
+	ConstraintToolCloth
+	ConstraintToolGlue
+)
+
+const constraintToolNames = "None;Link;Chain;Cloth;Glue"
+
+var (
+	activeConstraintTool ConstraintTool
+	linkStart            *physics.Unit
+)
+
+func handleConstraintTool(s *physics.Simulation) {
+	mousePosition := worldMousePosition()
+
+	if activeConstraintTool == ConstraintToolLink {
+		handleLinkTool(s, mousePosition)
+		return
+	}
+
+	if !isDragging {
+		if rl.IsMouseButtonPressed(rl.MouseLeftButton) && isMouseInViewport(s) {
+			startDrag(mousePosition)
+		}
+		return
+	}
+
+	if updateDrag(mousePosition, minSpawnPolygonPointDistance) {
+		switch activeConstraintTool {
+		case ConstraintToolChain:
+			s.NewChain(dragStart, mousePosition)
+		case ConstraintToolCloth:
+			s.NewGrid(utils.RectangleFromCorners(dragStart, mousePosition))
+		case ConstraintToolGlue:
+			if len(dragPoints) >= 3 {
+				s.GlueUnitsIn(dragPoints)
+			}
+		}
+	}
+}
+
+func handleLinkTool(s *physics.Simulation, mousePosition rl.Vector2) {
+	if !rl.IsMouseButtonPressed(rl.MouseLeftButton) || !isMouseInViewport(s) {
+		return
+	}
+
+	unit := s.UnitAt(mousePosition)
+	if unit == nil {
+		linkStart = nil
+		return
+	}
+
+	if linkStart == nil {
+		linkStart = unit
+		return
+	}
+
+	if unit != linkStart {
+		s.NewConstraint(linkStart, unit)
+	}
+	linkStart = nil
+}
+
+func drawConstraintPreview(s *physics.Simulation) {
+	if activeConstraintTool == ConstraintNone {
+		return
+	}
+
+	cursor := toPixelsV(worldMousePosition())
+
+	if activeConstraintTool == ConstraintToolLink {
+		if linkStart != nil {
+			start := toPixelsV(linkStart.Position)
+			rl.DrawCircleLines(int32(start.X), int32(start.Y), toPixels(linkStart.Radius)+2, rl.Black)
+			rl.DrawLineV(start, cursor, rl.DarkGray)
+		}
+		return
+	}
+
+	if !isDragging {
+		return
+	}
+
+	start := toPixelsV(dragStart)
+	switch activeConstraintTool {
+	case ConstraintToolChain:
+		rl.DrawLineV(start, cursor, rl.DarkGray)
+	case ConstraintToolCloth:
+		rl.DrawRectangleLinesEx(utils.RectangleFromCorners(start, cursor), 1, rl.DarkGray)
+	case ConstraintToolGlue:
+		drawDragPath(rl.DarkGray)
+	}
+}
+
+func drawRigidBodies(s *physics.Simulation) {
+	for _, body := range s.RigidBodies {
+		center := toPixelsV(body.CenterOfMass)
+		direction := rl.Vector2{X: float32(math.Cos(float64(body.Angle))), Y: float32(math.Sin(float64(body.Angle)))}
+
+		rl.DrawCircleV(center, 3/camera.Zoom, rl.Black)
+		rl.DrawLineV(center, rl.Vector2Add(center, rl.Vector2Scale(direction, 20/camera.Zoom)), rl.Black)
+	}
+}
+
+func drawConstraints(s *physics.Simulation) {
+	for _, c := range s.Constraints {
+		color := rl.Black
+		if !c.Rigid {
+			strain := rl.Clamp(s.Strain(c), -1, 1)
+			if strain < 0 {
+				strain = -strain
+			}
+			color = rl.ColorAlpha(rl.Red, 0.3+0.7*strain)
+		}
+
+		rl.DrawLineV(toPixelsV(c.A.Position), toPixelsV(s.LinkEnd(c)), color)
+	}
+}
@@ -0,0 +1,154 @@
+package gui
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/alexanderi96/go-fluid-simulator/physics"
+	"github.com/alexanderi96/go-fluid-simulator/utils"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const instancingVertexShader = `#version 330
+in vec3 vertexPosition;
+in vec3 vertexNormal;
+in mat4 instanceTransform;
+
+uniform mat4 mvp;
+
+out vec3 fragNormal;
+
+void main()
+{
+    fragNormal = normalize(mat3(instanceTransform)*vertexNormal);
+    gl_Position = mvp*instanceTransform*vec4(vertexPosition, 1.0);
+}
+`
+
+const instancingFragmentShader = `#version 330
+in vec3 fragNormal;
+
+uniform vec4 colDiffuse;
+
+out vec4 finalColor;
+
+void main()
+{
+    float light = 0.35 + 0.65*max(dot(fragNormal, normalize(vec3(0.4, 1.0, 0.3))), 0.0);
+    finalColor = vec4(colDiffuse.rgb*light, colDiffuse.a);
+}
+`
+
+// Every instanced draw call shares a single colour.
+const speedColorBuckets = 16
+
+var (
+	orbitYaw      float32 = math.Pi / 4
+	orbitPitch    float32 = math.Pi / 6
+	orbitDistance float32
+
+	sphereMesh        rl.Mesh
+	instancedMaterial rl.Material
+	instancingLoaded  bool
+)
+
+func loadInstancing() {
+	shader := rl.LoadShaderFromMemory(instancingVertexShader, instancingFragmentShader)
+	shader.UpdateLocation(rl.LocMatrixMvp, rl.GetShaderLocation(shader, "mvp"))
+	shader.UpdateLocation(rl.LocMatrixModel, rl.GetShaderLocationAttrib(shader, "instanceTransform"))
+	shader.UpdateLocation(rl.LocColorDiffuse, rl.GetShaderLocation(shader, "colDiffuse"))
+
+	sphereMesh = rl.GenMeshSphere(1, 12, 16)
+	instancedMaterial = rl.LoadMaterialDefault()
+	instancedMaterial.Shader = shader
+
+	instancingLoaded = true
+}
+
+func worldCenter3D(s *physics.Simulation) rl.Vector3 {
+	return rl.Vector3{X: s.Config.GameX / 2, Y: s.Config.GameY / 2, Z: s.Config.GameZ / 2}
+}
+
+func orbitCamera(s *physics.Simulation) rl.Camera3D {
+	if orbitDistance == 0 {
+		orbitDistance = 2 * float32(math.Max(float64(s.Config.GameX), math.Max(float64(s.Config.GameY), float64(s.Config.GameZ))))
+	}
+
+	target := worldCenter3D(s)
+	offset := rl.Vector3{
+		X: orbitDistance * float32(math.Cos(float64(orbitPitch))*math.Sin(float64(orbitYaw))),
+		Y: orbitDistance * float32(math.Sin(float64(orbitPitch))),
+		Z: orbitDistance * float32(math.Cos(float64(orbitPitch))*math.Cos(float64(orbitYaw))),
+	}
+
+	return rl.Camera3D{
+		Position:   rl.Vector3Add(target, offset),
+		Target:     target,
+		Up:         rl.Vector3{X: 0, Y: 1, Z: 0},
+		Fovy:       45,
+		Projection: rl.CameraPerspective,
+	}
+}
+
+func handleInput3D(s *physics.Simulation) {
+	if !isMouseInViewport(s) {
+		return
+	}
+
+	if rl.IsMouseButtonPressed(rl.MouseLeftButton) {
+		s.NewFluid3D()
+	} else if rl.IsMouseButtonPressed(rl.MouseRightButton) {
+		s.NewFluid3DWithVelocity()
+	}
+
+	if rl.IsMouseButtonDown(rl.MouseMiddleButton) {
+		delta := rl.GetMouseDelta()
+		orbitYaw -= delta.X * 0.01
+		orbitPitch = rl.Clamp(orbitPitch+delta.Y*0.01, -math.Pi/2+0.01, math.Pi/2-0.01)
+	}
+
+	if wheel := rl.GetMouseWheelMove(); wheel != 0 {
+		orbitDistance = float32(math.Max(0.1, float64(orbitDistance*(1-wheel*zoomPerWheel))))
+	}
+}
+
+func draw3D(s *physics.Simulation) {
+	if !instancingLoaded {
+		loadInstancing()
+	}
+
+	rl.BeginMode3D(orbitCamera(s))
+
+	size := rl.Vector3{X: s.Config.GameX, Y: s.Config.GameY, Z: s.Config.GameZ}
+	rl.DrawCubeWiresV(worldCenter3D(s), size, rl.DarkGray)
+
+	for col, transforms := range sphereTransforms(s) {
+		instancedMaterial.GetMap(rl.MapDiffuse).Color = col
+		rl.DrawMeshInstanced(sphereMesh, instancedMaterial, transforms, len(transforms))
+	}
+
+	rl.EndMode3D()
+}
+
+func sphereTransforms(s *physics.Simulation) map[color.RGBA][]rl.Matrix {
+	groups := map[color.RGBA][]rl.Matrix{}
+
+	for _, unit := range s.Fluid3D {
+		col := unit.Color
+		if s.Config.ShowSpeedColor {
+			velocity := rl.Vector3Scale(unit.GetVelocityWithVerlet(), 1/float32(math.Max(float64(s.Metrics.Timestep), 1e-6)))
+			speed := rl.Vector3Length(velocity)
+			bucket := float32(math.Round(float64(rl.Clamp(speed/speedColorReference, 0, 1) * speedColorBuckets)))
+			col = utils.GetColorFromVelocity(rl.Vector2{X: bucket / speedColorBuckets * speedColorReference}, speedColorReference)
+		}
+
+		transform := rl.MatrixMultiply(
+			rl.MatrixScale(unit.Radius, unit.Radius, unit.Radius),
+			rl.MatrixTranslate(unit.Position.X, unit.Position.Y, unit.Position.Z),
+		)
+		groups[col] = append(groups[col], transform)
+	}
+
+	return groups
+}
@@ -0,0 +1,130 @@
+package gui
+
+import (
+	"github.com/alexanderi96/go-fluid-simulator/physics"
+	"github.com/alexanderi96/go-fluid-simulator/utils"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type FieldTool int32
+
+const (
+	FieldNone FieldTool = iota
+	FieldAttractor
+	FieldRepulsor
+	FieldVortex
+	FieldWind
+	FieldTurbulence
+)
+
+const fieldToolNames = "None;Attractor;Repulsor;Vortex;Wind;Turbulence"
+
+const fieldHandleRadius = 8
+
+var (
+	activeFieldTool FieldTool
+	draggedField    physics.ForceField
+)
+
+func handleFieldTool(s *physics.Simulation) {
+	mousePosition := worldMousePosition()
+
+	if draggedField != nil {
+		draggedField.MoveTo(mousePosition)
+		s.WakeAll()
+		if rl.IsMouseButtonReleased(rl.MouseLeftButton) {
+			draggedField = nil
+		}
+		return
+	}
+
+	if !isDragging {
+		if rl.IsMouseButtonPressed(rl.MouseLeftButton) && isMouseInViewport(s) {
+			if field := s.FieldAt(mousePosition, toMetres(fieldHandleRadius/camera.Zoom)); field != nil {
+				draggedField = field
+				return
+			}
+			startDrag(mousePosition)
+		}
+		return
+	}
+
+	if updateDrag(mousePosition, 0) {
+		if field := fieldPreview(s, mousePosition); field != nil {
+			s.AddField(field)
+		}
+	}
+}
+
+func fieldPreview(s *physics.Simulation, cursor rl.Vector2) physics.ForceField {
+	distance := rl.Vector2Distance(dragStart, cursor)
+	if distance == 0 {
+		return nil
+	}
+
+	falloff := physics.FalloffInverseSquare
+	if s.Config.FieldLinearFalloff {
+		falloff = physics.FalloffLinear
+	}
+
+	switch activeFieldTool {
+	case FieldAttractor:
+		return physics.NewPointField(dragStart, s.Config.FieldStrength, distance, falloff)
+	case FieldRepulsor:
+		return physics.NewPointField(dragStart, -s.Config.FieldStrength, distance, falloff)
+	case FieldVortex:
+		return physics.NewVortex(dragStart, s.Config.FieldStrength, distance)
+	case FieldWind:
+		blow := rl.Vector2Scale(rl.Vector2Subtract(cursor, dragStart), s.Config.FieldStrength/distance)
+		return physics.NewWind(utils.RectangleFromCorners(dragStart, cursor), blow)
+	case FieldTurbulence:
+		return physics.NewTurbulence(utils.RectangleFromCorners(dragStart, cursor), s.Config.FieldStrength)
+	default:
+		return nil
+	}
+}
+
+func drawFieldPreview(s *physics.Simulation) {
+	if !isDragging || activeFieldTool == FieldNone {
+		return
+	}
+
+	if field := fieldPreview(s, worldMousePosition()); field != nil {
+		drawField(field, 0.5)
+	}
+}
+
+func drawFields(s *physics.Simulation) {
+	for _, field := range s.Fields {
+		drawField(field, 1)
+	}
+}
+
+func drawField(field physics.ForceField, alpha float32) {
+	switch field := field.(type) {
+	case *physics.PointField:
+		color := rl.DarkGreen
+		if field.Strength < 0 {
+			color = rl.Maroon
+		}
+		drawCircleOutline(field.Center, field.Radius, rl.Fade(color, alpha))
+	case *physics.Vortex:
+		drawCircleOutline(field.Center, field.Radius, rl.Fade(rl.DarkPurple, alpha))
+		tangent := rl.Vector2{X: field.Center.X, Y: field.Center.Y + field.Radius}
+		rl.DrawLineEx(toPixelsV(tangent), toPixelsV(rl.Vector2Add(tangent, rl.Vector2{X: -field.Radius / 4})), 2, rl.Fade(rl.DarkPurple, alpha))
+	case *physics.Wind:
+		region := toPixelsRectangle(field.Region)
+		rl.DrawRectangleLinesEx(region, 1, rl.Fade(rl.SkyBlue, alpha))
+		center := toPixelsV(field.Anchor())
+		rl.DrawLineEx(center, rl.Vector2Add(center, toPixelsV(rl.Vector2Scale(field.Blow, 0.05))), 2, rl.Fade(rl.SkyBlue, alpha))
+	case *physics.Turbulence:
+		rl.DrawRectangleLinesEx(toPixelsRectangle(field.Region), 1, rl.Fade(rl.Orange, alpha))
+	}
+
+	rl.DrawCircleV(toPixelsV(field.Anchor()), fieldHandleRadius/camera.Zoom/2, rl.Fade(rl.Black, alpha))
+}
+
+func drawCircleOutline(center rl.Vector2, radius float32, color rl.Color) {
+	rl.DrawRing(toPixelsV(center), toPixels(radius), toPixels(radius)+1/camera.Zoom, 0, 360, 64, color)
+}
@@ -2,8 +2,10 @@ package gui
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 
+	"github.com/alexanderi96/go-fluid-simulator/config"
 	"github.com/alexanderi96/go-fluid-simulator/physics"
 	"github.com/alexanderi96/go-fluid-simulator/utils"
 
@@ -15,14 +17,45 @@ func Draw(s *physics.Simulation) {
 	rl.BeginDrawing()
 	rl.ClearBackground(rl.LightGray)
 
-	drawSidebar(s)
-	drawFluid(s)
+	rl.BeginScissorMode(0, 0, s.Config.ViewportX, s.Config.ViewportY)
+
+	if s.Config.Mode3D {
+		draw3D(s)
+	} else {
+		rl.BeginMode2D(camera)
+
+		drawContainer(s)
+		drawObstacles(s)
+		drawFields(s)
+		if s.Config.ApplySelfGravity && s.Config.ShowQuadtree {
+			drawGravityTree(s)
+		}
+		drawConstraints(s)
+		drawFluid(s)
+		if s.Config.ApplyCohesion && s.Config.ShowCohesion {
+			drawCohesionBonds(s)
+		}
+		drawRigidBodies(s)
+		drawSpawnPreview(s)
+		drawObstaclePreview(s)
+		drawConstraintPreview(s)
+		drawFieldPreview(s)
+		drawInteractionCursor(s)
+
+		rl.EndMode2D()
 
-	if s.Config.ShowOverlay {
-		for _, unit := range s.Fluid {
-			drawOverlay(unit)
+		drawInteractionLabel(s)
+
+		if s.Config.ShowOverlay {
+			for _, unit := range s.Fluid {
+				drawOverlay(s, unit)
+			}
 		}
 	}
+
+	rl.EndScissorMode()
+
+	drawSidebar(s)
 	rl.EndDrawing()
 
 }
@@ -32,7 +65,7 @@ func drawSidebar(s *physics.Simulation) error {
 
 	border := int32(10)
 	xStart := s.Config.ViewportX + border
-	yStartTop := border
+	yStartTop := border - int32(scrollSidebar(s))
 
 	sliderLength := float32(s.Config.SidebarWidth - 2*border)
 	sliderThickness := float32(20)
@@ -42,6 +75,10 @@ func drawSidebar(s *physics.Simulation) error {
 	rl.DrawText(frametime, xStart, yStartTop, 20, rl.Black)
 	yStartTop += 20 + 5
 
+	timestep := fmt.Sprintf("Timestep: %.5f s x %d", s.Metrics.Timestep, s.Metrics.Substeps)
+	rl.DrawText(timestep, xStart, yStartTop, 20, rl.Black)
+	yStartTop += 20 + 5
+
 	fps := fmt.Sprintf("FPS: %d", s.Metrics.FPS)
 	rl.DrawText(fps, xStart, yStartTop, 20, rl.Black)
 	yStartTop += 20 + 5
@@ -50,24 +87,449 @@ func drawSidebar(s *physics.Simulation) error {
 	rl.DrawText(heapSize, xStart, yStartTop, 20, rl.Black)
 	yStartTop += 20 + 5
 
+	kineticEnergy := fmt.Sprintf("Kinetic Energy: %.3f J", s.KineticEnergy())
+	rl.DrawText(kineticEnergy, xStart, yStartTop, 20, rl.Black)
+	yStartTop += 20 + 5
+
 	quadtree := fmt.Sprintf("Using qTree: %t", s.Config.UseExperimentalQuadtree)
 	rl.DrawText(quadtree, xStart, yStartTop, 20, rl.Black)
+	yStartTop += 20 + 5
+
+	world := fmt.Sprintf("World: %.2fx%.2f m (zoom %.2f)", s.Config.GameX, s.Config.GameY, camera.Zoom)
+	rl.DrawText(world, xStart, yStartTop, 20, rl.Black)
+	yStartTop += 20 + 5
+
+	if gui.Button(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "Fit to World") {
+		fitCameraToWorld(s)
+	}
 	yStartTop += 40 + 5
 
 	selectedUnitNumbers := fmt.Sprintf("Selected Units: %d", s.Config.ParticleNumber)
 	rl.DrawText(selectedUnitNumbers, xStart, yStartTop, 20, rl.Black)
 	yStartTop += 20 + 5
 
-	unitNumbers := fmt.Sprintf("Spawned Units: %d", len(s.Fluid))
+	unitNumbers := fmt.Sprintf("Spawned Units: %d", len(s.Fluid)+len(s.Fluid3D))
 	rl.DrawText(unitNumbers, xStart, yStartTop, 20, rl.Black)
 	yStartTop += 20 + 5
 
 	s.Config.ParticleNumber = int32(gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", float32(s.Config.ParticleNumber), 1, 1000))
 	yStartTop += 20 + 5
 
+	if s.Config.Mode3D {
+		rl.DrawText("Greyed out: 2D only", xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+	}
+
+	disableIn3D(s)
+	rl.DrawText("Spawn Tool", xStart, yStartTop, 20, rl.Black)
+	yStartTop += 20 + 5
+
+	activeSpawnTool = SpawnTool(gui.ComboBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, spawnToolNames, int32(activeSpawnTool)))
+	yStartTop += 20 + 5
+
+	gui.Enable()
+
+	rl.DrawText("Material", xStart, yStartTop, 20, rl.Black)
+	yStartTop += 20 + 5
+
+	selectMaterial(s, rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness})
+	yStartTop += 20 + 5
+
+	disableIn3D(s)
+
+	obstacles := fmt.Sprintf("Obstacle Tool (%d placed)", len(s.Obstacles))
+	rl.DrawText(obstacles, xStart, yStartTop, 20, rl.Black)
+	yStartTop += 20 + 5
+
+	obstacleTool := ObstacleTool(gui.ComboBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, obstacleToolNames, int32(activeObstacleTool)))
+	if obstacleTool != activeObstacleTool && obstacleTool != ObstacleNone {
+		clearTools()
+	}
+	activeObstacleTool = obstacleTool
+	yStartTop += 20 + 5
+
+	if gui.Button(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "Clear Obstacles") {
+		s.ClearObstacles()
+	}
+	yStartTop += 20 + 5
+
+	constraints := fmt.Sprintf("Constraint Tool (%d links)", len(s.Constraints))
+	rl.DrawText(constraints, xStart, yStartTop, 20, rl.Black)
+	yStartTop += 20 + 5
+
+	constraintTool := ConstraintTool(gui.ComboBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, constraintToolNames, int32(activeConstraintTool)))
+	if constraintTool != activeConstraintTool && constraintTool != ConstraintNone {
+		clearTools()
+	}
+	activeConstraintTool = constraintTool
+	yStartTop += 20 + 5
+
+	fields := fmt.Sprintf("Force Field Tool (%d placed)", len(s.Fields))
+	rl.DrawText(fields, xStart, yStartTop, 20, rl.Black)
+	yStartTop += 20 + 5
+
+	fieldTool := FieldTool(gui.ComboBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, fieldToolNames, int32(activeFieldTool)))
+	if fieldTool != activeFieldTool && fieldTool != FieldNone {
+		clearTools()
+	}
+	activeFieldTool = fieldTool
+	yStartTop += 20 + 5
+
+	if activeFieldTool != FieldNone {
+		fieldStrength := fmt.Sprintf("Field Strength: %.1f m/s^2", s.Config.FieldStrength)
+		rl.DrawText(fieldStrength, xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+
+		s.Config.FieldStrength = gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", s.Config.FieldStrength, 0, 50)
+		yStartTop += 20 + 5
+
+		s.Config.FieldLinearFalloff = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, "Linear Falloff", s.Config.FieldLinearFalloff)
+		yStartTop += 20 + 5
+	}
+
+	if gui.Button(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "Clear Fields") {
+		s.ClearFields()
+	}
+	yStartTop += 20 + 5
+
+	rl.DrawText("Mouse Tool (T to cycle)", xStart, yStartTop, 20, rl.Black)
+	yStartTop += 20 + 5
+
+	interactionTool := physics.InteractionKind(gui.ComboBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, interactionToolNames, int32(activeInteractionTool)))
+	if interactionTool != activeInteractionTool && interactionTool != physics.InteractionNone {
+		clearTools()
+	}
+	activeInteractionTool = interactionTool
+	yStartTop += 20 + 5
+
+	s.Config.RigidConstraints = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, "Rigid Links", s.Config.RigidConstraints)
+	yStartTop += 20 + 5
+
+	if activeSpawnTool == SpawnSoftBody {
+		softBodyParticles := fmt.Sprintf("Soft Body Units: %d", s.Config.SoftBodyParticles)
+		rl.DrawText(softBodyParticles, xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+
+		s.Config.SoftBodyParticles = int32(gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", float32(s.Config.SoftBodyParticles), 3, 100))
+		yStartTop += 20 + 5
+	}
+
+	rl.DrawText("Container", xStart, yStartTop, 20, rl.Black)
+	yStartTop += 20 + 5
+
+	containerShape := int32(0)
+	if s.Config.ContainerShape == config.ContainerCircle {
+		containerShape = 1
+	}
+	if gui.ComboBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "Rectangle;Circle", containerShape) == 1 {
+		s.Config.ContainerShape = config.ContainerCircle
+	} else {
+		s.Config.ContainerShape = config.ContainerRectangle
+	}
+	yStartTop += 20 + 5
+
+	if s.Config.ContainerShape != config.ContainerCircle {
+		s.Config.OpenTop = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, "Open Top", s.Config.OpenTop)
+		yStartTop += 20 + 5
+
+		s.Config.OpenFloor = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, "Open Floor", s.Config.OpenFloor)
+		yStartTop += 20 + 5
+
+		s.Config.PeriodicX = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, "Periodic X", s.Config.PeriodicX)
+		yStartTop += 20 + 5
+
+		s.Config.PeriodicY = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, "Periodic Y", s.Config.PeriodicY)
+		yStartTop += 20 + 5
+	}
+
+	unitFriction := fmt.Sprintf("Unit Friction: %.2f", s.Config.UnitFriction)
+	rl.DrawText(unitFriction, xStart, yStartTop, 20, rl.Black)
+	yStartTop += 20 + 5
+
+	s.Config.UnitFriction = gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", s.Config.UnitFriction, 0, 1)
+	yStartTop += 20 + 5
+
+	wallFriction := fmt.Sprintf("Wall Friction: %.2f", s.Config.WallFriction)
+	rl.DrawText(wallFriction, xStart, yStartTop, 20, rl.Black)
+	yStartTop += 20 + 5
+
+	s.Config.WallFriction = gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", s.Config.WallFriction, 0, 1)
+	yStartTop += 20 + 5
+
+	s.Config.ApplyLinearDrag = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, fmt.Sprintf("Linear Drag: %.2f /s", s.Config.LinearDrag), s.Config.ApplyLinearDrag)
+	yStartTop += 20 + 5
+
+	if s.Config.ApplyLinearDrag {
+		s.Config.LinearDrag = gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", s.Config.LinearDrag, 0, 5)
+		yStartTop += 20 + 5
+	}
+
+	s.Config.ApplyAirDrag = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, fmt.Sprintf("Air Drag: %.2f kg/m^2", s.Config.AirDrag), s.Config.ApplyAirDrag)
+	yStartTop += 20 + 5
+
+	if s.Config.ApplyAirDrag {
+		s.Config.AirDrag = gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", s.Config.AirDrag, 0, 10)
+		yStartTop += 20 + 5
+	}
+
+	s.Config.ApplyCCD = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, "Continuous Collisions", s.Config.ApplyCCD)
+	yStartTop += 20 + 5
+
+	s.Config.ApplySleep = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, "Sleeping", s.Config.ApplySleep)
+	yStartTop += 20 + 5
+
+	if s.Config.ApplySleep {
+		s.Config.ShowSleeping = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, "Tint Sleeping", s.Config.ShowSleeping)
+		yStartTop += 20 + 5
+	}
+
+	s.Config.ApplyXSPH = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, fmt.Sprintf("XSPH Smoothing: %.2f", s.Config.XSPHSmoothing), s.Config.ApplyXSPH)
+	yStartTop += 20 + 5
+
+	if s.Config.ApplyXSPH {
+		s.Config.XSPHSmoothing = gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", s.Config.XSPHSmoothing, 0, 1)
+		yStartTop += 20 + 5
+	}
+
+	s.Config.ApplyCohesion = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, fmt.Sprintf("Cohesion: %.1f N", s.Config.CohesionStrength), s.Config.ApplyCohesion)
+	yStartTop += 20 + 5
+
+	if s.Config.ApplyCohesion {
+		s.Config.CohesionStrength = gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", s.Config.CohesionStrength, 0, 200)
+		yStartTop += 20 + 5
+
+		cohesionRange := fmt.Sprintf("Cohesion Range: %.3f m", s.Config.CohesionRange)
+		rl.DrawText(cohesionRange, xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+
+		s.Config.CohesionRange = gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", s.Config.CohesionRange, 0, 0.5)
+		yStartTop += 20 + 5
+
+		s.Config.ShowCohesion = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, "Show Cohesion Bonds", s.Config.ShowCohesion)
+		yStartTop += 20 + 5
+	}
+
+	rl.DrawText("Unit Interaction", xStart, yStartTop, 20, rl.Black)
+	yStartTop += 20 + 5
+
+	interactionMode := int32(0)
+	if s.Config.InteractionMode == config.InteractionLennardJones {
+		interactionMode = 1
+	}
+	if gui.ComboBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "Hard Disc;Lennard-Jones", interactionMode) == 1 {
+		s.Config.InteractionMode = config.InteractionLennardJones
+	} else {
+		s.Config.InteractionMode = config.InteractionHardDisc
+	}
+	yStartTop += 20 + 5
+
+	if s.Config.InteractionMode == config.InteractionLennardJones {
+		epsilon := fmt.Sprintf("Epsilon: %.2f J", s.Config.LJEpsilon)
+		rl.DrawText(epsilon, xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+
+		s.Config.LJEpsilon = gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", s.Config.LJEpsilon, 0.1, 20)
+		yStartTop += 20 + 5
+
+		sigma := fmt.Sprintf("Sigma: %.3f m", s.Config.LJSigma)
+		rl.DrawText(sigma, xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+
+		s.Config.LJSigma = gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", s.Config.LJSigma, 0.05, 0.5)
+		yStartTop += 20 + 5
+
+		potential := fmt.Sprintf("LJ Potential: %.3g J", s.LennardJonesPotentialEnergy())
+		rl.DrawText(potential, xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+	} else {
+		iterations := fmt.Sprintf("Solver Iterations: %d", s.Config.SolverIterations)
+		rl.DrawText(iterations, xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+
+		s.Config.SolverIterations = int(gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", float32(s.Config.SolverIterations), 1, 20))
+		yStartTop += 20 + 5
+
+		ordering := int32(0)
+		if s.Config.SolverOrdering == config.SolverJacobi {
+			ordering = 1
+		}
+		if gui.ComboBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "Gauss-Seidel;Jacobi", ordering) == 1 {
+			s.Config.SolverOrdering = config.SolverJacobi
+		} else {
+			s.Config.SolverOrdering = config.SolverGaussSeidel
+		}
+		yStartTop += 20 + 5
+
+		relaxation := fmt.Sprintf("Over-relaxation: %.2f", s.Config.OverRelaxation)
+		rl.DrawText(relaxation, xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+
+		s.Config.OverRelaxation = gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", s.Config.OverRelaxation, 0.5, 2)
+		yStartTop += 20 + 5
+
+		maxOverlap := fmt.Sprintf("Max Overlap: %.2g m", s.Metrics.MaxResidualOverlap)
+		rl.DrawText(maxOverlap, xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+
+		meanOverlap := fmt.Sprintf("Mean Overlap: %.2g m", s.Metrics.MeanResidualOverlap)
+		rl.DrawText(meanOverlap, xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+	}
+
+	thermostats := []string{config.ThermostatNone, config.ThermostatRescale, config.ThermostatBerendsen}
+	thermostat := int32(0)
+	for i, name := range thermostats {
+		if s.Config.Thermostat == name {
+			thermostat = int32(i)
+		}
+	}
+
+	temperature := fmt.Sprintf("Thermostat (T* = %.3f)", s.KineticTemperature())
+	rl.DrawText(temperature, xStart, yStartTop, 20, rl.Black)
+	yStartTop += 20 + 5
+
+	thermostat = gui.ComboBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "None;Rescale;Berendsen", thermostat)
+	s.Config.Thermostat = thermostats[thermostat]
+	yStartTop += 20 + 5
+
+	if s.Config.Thermostat != config.ThermostatNone {
+		target := fmt.Sprintf("Target T*: %.3f", s.Config.TargetTemperature)
+		rl.DrawText(target, xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+
+		s.Config.TargetTemperature = gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", s.Config.TargetTemperature, 0, 2)
+		yStartTop += 20 + 5
+	}
+
+	integrator := int32(0)
+	for i, name := range config.Integrators {
+		if s.Config.Integrator == name {
+			integrator = int32(i)
+		}
+	}
+
+	rl.DrawText("Integrator", xStart, yStartTop, 20, rl.Black)
+	yStartTop += 20 + 5
+
+	integrator = gui.ComboBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, integratorNames, integrator)
+	s.Config.Integrator = config.Integrators[integrator]
+	yStartTop += 20 + 5
+
+	select {
+	case drifts := <-integratorComparison:
+		integratorDrifts = drifts
+		integratorComparison = nil
+	default:
+	}
+
+	compare := "Compare Integrators"
+	if integratorComparison != nil {
+		compare = "Comparing..."
+	}
+	if gui.Button(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, compare) && integratorComparison == nil {
+		integratorComparison = s.CompareIntegrators(s.Config.ComparisonDuration, comparisonStep)
+	}
+	yStartTop += 20 + 5
+
+	for i, result := range integratorDrifts {
+		drift := fmt.Sprintf("%s: %+.2f%%", integratorLabels[i], 100*result.Drift)
+		rl.DrawText(drift, xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+	}
+
+	selfGravity := gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, "Self Gravity", s.Config.ApplySelfGravity)
+	if selfGravity && !s.Config.ApplySelfGravity {
+		s.ResetEnergyReference()
+	}
+	s.Config.ApplySelfGravity = selfGravity
+	yStartTop += 20 + 5
+
+	if s.Config.ApplySelfGravity {
+		theta := fmt.Sprintf("Theta: %.2f", s.Config.BarnesHutTheta)
+		rl.DrawText(theta, xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+
+		s.Config.BarnesHutTheta = gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", s.Config.BarnesHutTheta, 0, 1.5)
+		yStartTop += 20 + 5
+
+		softening := fmt.Sprintf("Softening: %.3f m", s.Config.GravitySoftening)
+		rl.DrawText(softening, xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+
+		s.Config.GravitySoftening = gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", s.Config.GravitySoftening, 0, 0.5)
+		yStartTop += 20 + 5
+
+		energy := fmt.Sprintf("K %.3g J  U %.3g J", s.Energy.Kinetic, s.Energy.Potential)
+		rl.DrawText(energy, xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+
+		drift := fmt.Sprintf("E %.3g J  Drift %+.2f%%", s.Energy.Total, 100*s.Energy.Drift)
+		rl.DrawText(drift, xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+
+		if gui.Button(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "Reset Energy Reference") {
+			s.ResetEnergyReference()
+		}
+		yStartTop += 20 + 5
+
+		s.Config.ShowQuadtree = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, "Show Quadtree", s.Config.ShowQuadtree)
+		yStartTop += 20 + 5
+	}
+
+	s.Config.ApplyHeat = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, "Heat Transfer", s.Config.ApplyHeat)
+	yStartTop += 20 + 5
+
+	if s.Config.ApplyHeat {
+		floorTemperature := fmt.Sprintf("Floor: %.0f K", s.Config.FloorTemperature)
+		rl.DrawText(floorTemperature, xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+
+		s.Config.FloorTemperature = gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", s.Config.FloorTemperature, 200, 500)
+		yStartTop += 20 + 5
+
+		ceilingTemperature := fmt.Sprintf("Ceiling: %.0f K", s.Config.CeilingTemperature)
+		rl.DrawText(ceilingTemperature, xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+
+		s.Config.CeilingTemperature = gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", s.Config.CeilingTemperature, 200, 500)
+		yStartTop += 20 + 5
+
+		s.Config.ApplyBuoyancy = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, "Thermal Buoyancy", s.Config.ApplyBuoyancy)
+		yStartTop += 20 + 5
+
+		s.Config.ShowTemperature = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, "Show Temperature", s.Config.ShowTemperature)
+		yStartTop += 20 + 5
+	}
+
+	gui.Enable()
+
+	s.Config.Mode3D = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, "3D Mode", s.Config.Mode3D)
+	yStartTop += 20 + 5
+
 	s.Config.ApplyGravity = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, "Apply Gravity", s.Config.ApplyGravity)
 	yStartTop += 20 + 5
 
+	if s.Config.ApplyGravity {
+		magnitude := s.Config.GravityMagnitude()
+		angle := s.Config.GravityAngle() * rl.Rad2deg
+
+		gravity := fmt.Sprintf("Gravity: %.2f m/s^2", magnitude)
+		rl.DrawText(gravity, xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+
+		magnitude = gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", magnitude, 0, 30)
+		yStartTop += 20 + 5
+
+		tilt := fmt.Sprintf("Tilt: %.0f deg (arrow keys)", angle)
+		rl.DrawText(tilt, xStart, yStartTop, 20, rl.Black)
+		yStartTop += 20 + 5
+
+		angle = gui.Slider(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: sliderLength, Height: sliderThickness}, "", "", angle, -180, 180)
+		yStartTop += 20 + 5
+
+		s.Config.SetGravity(magnitude, angle*rl.Deg2rad)
+	}
+
 	s.Config.SetRandomColor = gui.CheckBox(rl.Rectangle{X: float32(xStart), Y: float32(yStartTop), Width: 20, Height: 20}, "Set Random Color", s.Config.SetRandomColor)
 	yStartTop += 20 + 5
 
@@ -177,10 +639,84 @@ func drawSidebar(s *physics.Simulation) error {
 
 	}
 
+	sidebarHeight = float32(yStartTop+border) + sidebarScroll
+
 	return nil
 
 }
 
+const speedColorReference = 5
+
+var sleepingTint = rl.NewColor(40, 60, 160, 160)
+
+var sidebarScroll, sidebarHeight float32
+
+const integratorNames = "Verlet;Velocity Verlet;RK4"
+
+var integratorLabels = []string{"Verlet", "Velocity Verlet", "RK4"}
+
+// A fixed step keeps the comparison independent of the frame rate.
+const comparisonStep = 1.0 / 60
+
+var (
+	integratorDrifts     []physics.IntegratorDrift
+	integratorComparison <-chan []physics.IntegratorDrift
+)
+
+func disableIn3D(s *physics.Simulation) {
+	if s.Config.Mode3D {
+		gui.Disable()
+	}
+}
+
+func scrollSidebar(s *physics.Simulation) float32 {
+	if rl.GetMouseX() > s.Config.ViewportX {
+		sidebarScroll -= rl.GetMouseWheelMove() * 40
+	}
+	limit := float32(math.Max(0, float64(sidebarHeight-float32(s.Config.WindowHeight))))
+	sidebarScroll = rl.Clamp(sidebarScroll, 0, limit)
+	return sidebarScroll
+}
+
+func drawContainer(s *physics.Simulation) {
+	if s.Config.ContainerShape == config.ContainerCircle {
+		center, radius := s.CircleContainer()
+		rl.DrawRing(toPixelsV(center), toPixels(radius), toPixels(radius)+2/camera.Zoom, 0, 360, 128, rl.DarkGray)
+		return
+	}
+
+	width := toPixels(s.Config.GameX)
+	height := toPixels(s.Config.GameY)
+
+	rl.DrawRectangleLinesEx(rl.Rectangle{X: 0, Y: 0, Width: width, Height: height}, 1/camera.Zoom, rl.Gray)
+
+	if s.Config.PeriodicX {
+		rl.DrawLineV(rl.Vector2{X: 0, Y: 0}, rl.Vector2{X: 0, Y: height}, rl.SkyBlue)
+		rl.DrawLineV(rl.Vector2{X: width, Y: 0}, rl.Vector2{X: width, Y: height}, rl.SkyBlue)
+	}
+	if s.Config.PeriodicY {
+		rl.DrawLineV(rl.Vector2{X: 0, Y: 0}, rl.Vector2{X: width, Y: 0}, rl.SkyBlue)
+		rl.DrawLineV(rl.Vector2{X: 0, Y: height}, rl.Vector2{X: width, Y: height}, rl.SkyBlue)
+	} else if s.Config.ApplyHeat {
+		cold, hot := temperatureRange(s)
+		if !s.Config.OpenTop {
+			rl.DrawLineEx(rl.Vector2{X: 0, Y: 0}, rl.Vector2{X: width, Y: 0}, 3/camera.Zoom, utils.GetColorFromTemperature(s.Config.CeilingTemperature, cold, hot))
+		}
+		if !s.Config.OpenFloor {
+			rl.DrawLineEx(rl.Vector2{X: 0, Y: height}, rl.Vector2{X: width, Y: height}, 3/camera.Zoom, utils.GetColorFromTemperature(s.Config.FloorTemperature, cold, hot))
+		}
+	}
+}
+
+func temperatureRange(s *physics.Simulation) (float32, float32) {
+	cold := float32(math.Min(float64(s.Config.FloorTemperature), float64(s.Config.CeilingTemperature)))
+	hot := float32(math.Max(float64(s.Config.FloorTemperature), float64(s.Config.CeilingTemperature)))
+	if hot == cold {
+		return cold - 1, hot + 1
+	}
+	return cold, hot
+}
+
 func drawFluid(s *physics.Simulation) {
 	for _, unit := range s.Fluid {
 
@@ -189,36 +725,49 @@ func drawFluid(s *physics.Simulation) {
 			if s.Config.UseExperimentalQuadtree {
 				//color = utils.GetColorFromVelocity(unit.Velocity)
 			} else {
-				color = utils.GetColorFromVelocity(unit.GetVelocityWithVerlet())
+				color = utils.GetColorFromVelocity(unit.Velocity(s.Metrics.Timestep), speedColorReference)
 			}
 		}
+		if s.Config.ShowTemperature {
+			cold, hot := temperatureRange(s)
+			color = utils.GetColorFromTemperature(unit.Temperature, cold, hot)
+		}
+		if s.Config.ShowSleeping && unit.Asleep {
+			color = rl.ColorAlphaBlend(color, sleepingTint, rl.White)
+		}
 
 		if s.Config.ShowVectors {
-			drawVectors(unit)
+			drawVectors(s, unit)
 		}
 
-		rl.DrawCircleV(unit.Position, unit.Radius, color)
+		rl.DrawCircleV(toPixelsV(unit.Position), toPixels(unit.Radius), color)
+
+		if s.Config.ShowVectors {
+			drawOrientation(unit)
+		}
 	}
 }
 
-func drawOverlay(u *physics.Unit) {
-	mouseX := float32(rl.GetMouseX())
-	mouseY := float32(rl.GetMouseY())
-
-	if rl.CheckCollisionPointCircle(rl.NewVector2(mouseX, mouseY), u.Position, u.Radius) {
+func drawOverlay(s *physics.Simulation, u *physics.Unit) {
+	if rl.CheckCollisionPointCircle(worldMousePosition(), u.Position, u.Radius) {
 
 		overlayText := fmt.Sprintf(
-			"ID: %s\nRadius: %.2f\nMass: %.2f\nElasticity: %.2f",
+			"ID: %s\nMaterial: %s\nRadius: %.3f m\nMass: %.2f kg\nElasticity: %.2f\nSpeed: %.2f m/s\nKinetic Energy: %.3f J\nTemperature: %.1f K",
 			u.Id,
+			materialLabel(u),
 			u.Radius,
 			u.Mass,
 			u.Elasticity,
+			rl.Vector2Length(u.Velocity(s.Metrics.Timestep)),
+			u.KineticEnergy(s.Metrics.Timestep),
+			u.Temperature,
 		)
-		x := int32(u.Position.X + u.Radius + 10)
-		y := int32(u.Position.Y - u.Radius - 10)
+		corner := rl.GetWorldToScreen2D(toPixelsV(rl.Vector2{X: u.Position.X + u.Radius, Y: u.Position.Y - u.Radius}), camera)
+		x := int32(corner.X + 10)
+		y := int32(corner.Y - 10)
 
 		textWidth := rl.MeasureText(overlayText, 20)
-		textHeight := 35 * 3
+		textHeight := 35 * 5
 
 		rl.DrawRectangle(x-5, y-5, textWidth+10, int32(textHeight+10), rl.Color{255, 255, 255, 128})
 
@@ -226,13 +775,47 @@ func drawOverlay(u *physics.Unit) {
 	}
 }
 
-func drawVectors(u *physics.Unit) {
+func drawVectors(s *physics.Simulation, u *physics.Unit) {
+	position := toPixelsV(u.Position)
 
-	endVelocity := rl.Vector2Add(u.Position, rl.Vector2Scale(u.GetVelocityWithVerlet(), 0.1))
+	endVelocity := rl.Vector2Add(position, toPixelsV(rl.Vector2Scale(u.Velocity(s.Metrics.Timestep), 0.1)))
 
-	rl.DrawLineEx(u.Position, endVelocity, 2, rl.Blue)
+	rl.DrawLineEx(position, endVelocity, 2, rl.Blue)
 
-	endAcceleration := rl.Vector2Add(u.Position, rl.Vector2Scale(u.Acceleration, 0.1))
+	endAcceleration := rl.Vector2Add(position, toPixelsV(rl.Vector2Scale(u.Acceleration, 0.1)))
 
-	rl.DrawLineEx(u.Position, endAcceleration, 2, rl.Orange)
+	rl.DrawLineEx(position, endAcceleration, 2, rl.Orange)
+}
+
+func drawOrientation(u *physics.Unit) {
+	rim := rl.Vector2{
+		X: u.Position.X + u.Radius*float32(math.Cos(float64(u.Orientation))),
+		Y: u.Position.Y + u.Radius*float32(math.Sin(float64(u.Orientation))),
+	}
+
+	rl.DrawLineEx(toPixelsV(u.Position), toPixelsV(rim), 2, rl.Black)
+}
+
+// Bonds across a periodic edge are left out.
+func drawCohesionBonds(s *physics.Simulation) {
+	for _, bond := range s.CohesionBonds() {
+		a, b := bond[0], bond[1]
+		if rl.Vector2Distance(a.Position, b.Position) > a.Radius+b.Radius+s.Config.CohesionRange {
+			continue
+		}
+		rl.DrawLineV(toPixelsV(a.Position), toPixelsV(b.Position), rl.DarkBlue)
+	}
+}
+
+func materialLabel(u *physics.Unit) string {
+	if name := u.MaterialName(); name != "" {
+		return name
+	}
+	return "custom"
+}
+
+func drawGravityTree(s *physics.Simulation) {
+	for _, cell := range s.GravityCells() {
+		rl.DrawRectangleLinesEx(toPixelsRectangle(cell), 1/camera.Zoom, rl.Fade(rl.Gray, 0.4))
+	}
 }
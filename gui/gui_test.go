@@ -1 +1,36 @@
 package gui
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alexanderi96/go-fluid-simulator/config"
+	"github.com/alexanderi96/go-fluid-simulator/physics"
+)
+
+func TestFitCameraToWorld(t *testing.T) {
+	s := &physics.Simulation{Config: &config.Config{
+		ViewportX:   800,
+		ViewportY:   600,
+		GameX:       20,
+		GameY:       10,
+		ScaleFactor: 0.25,
+	}}
+
+	syncScale(s)
+	fitCameraToWorld(s)
+
+	if toPixels(1) != 4 || toMetres(toPixels(3)) != 3 {
+		t.Fatalf("1 m is %v px at 0.25 m/px", toPixels(1))
+	}
+
+	target := toMetresV(camera.Target)
+	if target.X != 10 || target.Y != 5 {
+		t.Fatalf("camera looks at %v, want the centre of the world", target)
+	}
+
+	// The world is 80x40 px, so the width is what limits the zoom.
+	if want := float32(10 * fitWorldMargin); math.Abs(float64(camera.Zoom-want)) > 1e-5 {
+		t.Fatalf("zoom %v, want %v", camera.Zoom, want)
+	}
+}
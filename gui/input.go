@@ -0,0 +1,60 @@
+package gui
+
+import (
+	"log"
+	"math"
+
+	"github.com/alexanderi96/go-fluid-simulator/physics"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func HandleInput(s *physics.Simulation) {
+	if rl.IsKeyPressed(rl.KeyR) {
+		s.Reset()
+	} else if rl.IsKeyPressed(rl.KeySpace) {
+		s.IsPause = !s.IsPause
+	} else if rl.IsKeyPressed(rl.KeyF5) {
+		if err := s.SaveScene(s.Config.SceneFile); err != nil {
+			log.Printf("Errore durante il salvataggio della scena: %v", err)
+		}
+	} else if rl.IsKeyPressed(rl.KeyF9) {
+		if err := s.LoadScene(s.Config.SceneFile); err != nil {
+			log.Printf("Errore durante il caricamento della scena: %v", err)
+		}
+	} else if rl.IsKeyPressed(rl.KeyF6) {
+		if err := s.ExportCSV(s.Config.ExportFile); err != nil {
+			log.Printf("Errore durante l'esportazione: %v", err)
+		}
+	} else if rl.IsKeyPressed(rl.KeyT) {
+		cycleInteractionTool()
+	} else if rl.IsMouseButtonPressed(rl.MouseRightButton) && isMouseInViewport(s) && !s.Config.Mode3D {
+		s.NewFluidWithVelocity(worldMousePosition())
+	}
+
+	handleTilt(s)
+
+	if s.Config.Mode3D {
+		handleInput3D(s)
+		return
+	}
+
+	handleCamera(s)
+	handleTools(s)
+}
+
+const tiltRate = math.Pi / 2
+
+func handleTilt(s *physics.Simulation) {
+	magnitude := s.Config.GravityMagnitude()
+	angle := s.Config.GravityAngle()
+
+	switch {
+	case rl.IsKeyDown(rl.KeyLeft):
+		s.Config.SetGravity(magnitude, angle+tiltRate*rl.GetFrameTime())
+	case rl.IsKeyDown(rl.KeyRight):
+		s.Config.SetGravity(magnitude, angle-tiltRate*rl.GetFrameTime())
+	case rl.IsKeyPressed(rl.KeyUp):
+		s.Config.SetGravity(magnitude, 0)
+	}
+}
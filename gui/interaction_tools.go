@@ -0,0 +1,86 @@
+package gui
+
+import (
+	"github.com/alexanderi96/go-fluid-simulator/physics"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const interactionToolNames = "None;Grab;Push;Pull;Stir"
+
+var interactionToolLabels = []string{"", "Grab", "Push", "Pull", "Stir"}
+
+var (
+	activeInteractionTool physics.InteractionKind
+	lastCursorPosition    rl.Vector2
+)
+
+func cycleInteractionTool() {
+	next := (activeInteractionTool + 1) % physics.InteractionKind(len(interactionToolLabels))
+	clearTools()
+	activeInteractionTool = next
+}
+
+func handleInteractionTool(s *physics.Simulation) {
+	mousePosition := worldMousePosition()
+
+	cursorVelocity := rl.Vector2{}
+	if frameTime := rl.GetFrameTime(); frameTime > 0 {
+		cursorVelocity = rl.Vector2Scale(rl.Vector2Subtract(mousePosition, lastCursorPosition), 1/frameTime)
+	}
+	lastCursorPosition = mousePosition
+
+	interaction := physics.Interaction{
+		Kind:     activeInteractionTool,
+		Position: mousePosition,
+		Velocity: cursorVelocity,
+		Radius:   s.Config.InteractionRadius,
+		Unit:     s.Interaction.Unit,
+	}
+
+	held := rl.IsMouseButtonDown(rl.MouseLeftButton) && (s.Interaction.Kind != physics.InteractionNone || isMouseInViewport(s))
+	if !held {
+		s.Interaction = physics.Interaction{}
+		return
+	}
+
+	if activeInteractionTool == physics.InteractionGrab && interaction.Unit == nil {
+		if !rl.IsMouseButtonPressed(rl.MouseLeftButton) {
+			return
+		}
+		if interaction.Unit = s.UnitAt(mousePosition); interaction.Unit == nil {
+			return
+		}
+	}
+
+	s.Interaction = interaction
+}
+
+func drawInteractionCursor(s *physics.Simulation) {
+	if activeInteractionTool == physics.InteractionNone {
+		return
+	}
+
+	cursor := worldMousePosition()
+	if activeInteractionTool == physics.InteractionGrab {
+		if unit := s.Interaction.Unit; unit != nil {
+			rl.DrawLineEx(toPixelsV(unit.Position), toPixelsV(cursor), 2/camera.Zoom, rl.DarkGray)
+		}
+		return
+	}
+
+	color := rl.Fade(rl.DarkGray, 0.3)
+	if s.Interaction.Kind != physics.InteractionNone {
+		color = rl.Fade(rl.DarkGray, 0.8)
+	}
+	drawCircleOutline(cursor, s.Config.InteractionRadius, color)
+}
+
+func drawInteractionLabel(s *physics.Simulation) {
+	if activeInteractionTool == physics.InteractionNone || !isMouseInViewport(s) {
+		return
+	}
+
+	mouse := rl.GetMousePosition()
+	rl.DrawText(interactionToolLabels[activeInteractionTool], int32(mouse.X)+12, int32(mouse.Y)+12, 20, rl.DarkGray)
+}
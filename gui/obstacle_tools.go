@@ -0,0 +1,102 @@
+package gui
+
+import (
+	"github.com/alexanderi96/go-fluid-simulator/physics"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type ObstacleTool int32
+
+const (
+	ObstacleNone ObstacleTool = iota
+	ObstacleToolSegment
+	ObstacleToolBox
+	ObstacleToolCircle
+	ObstacleToolPolygon
+)
+
+const obstacleToolNames = "None;Segment;Box;Circle;Polygon"
+
+const minObstaclePolygonPointDistance = 20
+
+var activeObstacleTool ObstacleTool
+
+func handleObstacleTool(s *physics.Simulation) {
+	mousePosition := worldMousePosition()
+
+	if !isDragging {
+		if rl.IsMouseButtonPressed(rl.MouseLeftButton) && isMouseInViewport(s) {
+			startDrag(mousePosition)
+		}
+		return
+	}
+
+	if updateDrag(mousePosition, minObstaclePolygonPointDistance) {
+		if obstacle := obstaclePreview(s, mousePosition); obstacle != nil {
+			s.AddObstacle(obstacle)
+		}
+	}
+}
+
+func obstaclePreview(s *physics.Simulation, cursor rl.Vector2) *physics.Obstacle {
+	if rl.Vector2Distance(dragStart, cursor) == 0 && activeObstacleTool != ObstacleToolPolygon {
+		return nil
+	}
+
+	switch activeObstacleTool {
+	case ObstacleToolSegment:
+		return physics.NewSegmentObstacle(dragStart, cursor)
+	case ObstacleToolBox:
+		return physics.NewBoxObstacle(dragStart, cursor, s.Config.ObstacleThickness)
+	case ObstacleToolCircle:
+		return physics.NewCircleObstacle(dragStart, rl.Vector2Distance(dragStart, cursor))
+	case ObstacleToolPolygon:
+		if len(dragPoints) < 3 {
+			return nil
+		}
+		points := make([]rl.Vector2, len(dragPoints))
+		copy(points, dragPoints)
+		return physics.NewPolygonObstacle(points)
+	default:
+		return nil
+	}
+}
+
+func drawObstaclePreview(s *physics.Simulation) {
+	if !isDragging || activeObstacleTool == ObstacleNone {
+		return
+	}
+
+	if activeObstacleTool == ObstacleToolPolygon {
+		drawDragPath(rl.DarkGray)
+		return
+	}
+
+	if obstacle := obstaclePreview(s, worldMousePosition()); obstacle != nil {
+		drawObstacle(obstacle, rl.Fade(rl.DarkGray, 0.5))
+	}
+}
+
+func drawObstacles(s *physics.Simulation) {
+	for _, obstacle := range s.Obstacles {
+		drawObstacle(obstacle, rl.DarkGray)
+	}
+}
+
+func drawObstacle(o *physics.Obstacle, color rl.Color) {
+	points := toPixelsPolygon(o.WorldPoints())
+
+	switch o.Shape {
+	case physics.ObstacleSegment:
+		if len(points) >= 2 {
+			rl.DrawLineEx(points[0], points[1], 2, color)
+		}
+	case physics.ObstacleCircle:
+		rl.DrawCircleV(toPixelsV(o.WorldCenter()), toPixels(o.Radius), color)
+	case physics.ObstaclePolygon:
+		for i := range points {
+			rl.DrawLineEx(points[i], points[(i+1)%len(points)], 2, color)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexanderi96/go-fluid-simulator/physics"
+	"github.com/alexanderi96/go-fluid-simulator/utils"
+
+	gui "github.com/gen2brain/raylib-go/raygui"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type SpawnTool int32
+
+const (
+	SpawnBlob SpawnTool = iota
+	SpawnRectangle
+	SpawnCircle
+	SpawnLine
+	SpawnPolygon
+	SpawnSoftBody
+	SpawnRigidBody
+)
+
+const spawnToolNames = "Blob;Rectangle;Circle;Line;Polygon;Soft Body;Rigid Body"
+
+const minSpawnPolygonPointDistance = 5
+
+var activeSpawnTool SpawnTool
+
+func materialNames(s *physics.Simulation) string {
+	names := []string{"Custom"}
+	for _, material := range s.Config.Materials {
+		names = append(names, material.Name)
+	}
+	return strings.Join(names, ";")
+}
+
+func selectMaterial(s *physics.Simulation, bounds rl.Rectangle) {
+	active := int32(0)
+	for i, material := range s.Config.Materials {
+		if material.Name == s.Config.ActiveMaterial {
+			active = int32(i + 1)
+		}
+	}
+
+	active = gui.ComboBox(bounds, materialNames(s), active)
+	if active == 0 {
+		s.Config.ActiveMaterial = ""
+	} else {
+		s.Config.ActiveMaterial = s.Config.Materials[active-1].Name
+	}
+}
+
+func handleSpawnTool(s *physics.Simulation) {
+	mousePosition := worldMousePosition()
+
+	if !isDragging {
+		if rl.IsMouseButtonPressed(rl.MouseLeftButton) && isMouseInViewport(s) {
+			if activeSpawnTool == SpawnBlob {
+				s.NewFluidAtPosition(mousePosition)
+				return
+			}
+
+			startDrag(mousePosition)
+		}
+		return
+	}
+
+	if updateDrag(mousePosition, minSpawnPolygonPointDistance) {
+		switch activeSpawnTool {
+		case SpawnSoftBody:
+			s.NewSoftBody(dragStart, rl.Vector2Distance(dragStart, mousePosition))
+		case SpawnRigidBody:
+			s.NewRigidBodyInPolygon(dragPoints)
+		default:
+			s.NewFluidAtPositions(spawnPreviewPositions(s, mousePosition))
+		}
+	}
+}
+
+func spawnPreviewPositions(s *physics.Simulation, cursor rl.Vector2) []rl.Vector2 {
+	switch activeSpawnTool {
+	case SpawnRectangle:
+		return physics.RectangleSpawnPositions(utils.RectangleFromCorners(dragStart, cursor), s.Config)
+	case SpawnCircle:
+		return physics.CircleSpawnPositions(dragStart, rl.Vector2Distance(dragStart, cursor), s.Config)
+	case SpawnLine:
+		return physics.LineSpawnPositions(dragStart, cursor, s.Config)
+	case SpawnPolygon, SpawnRigidBody:
+		return physics.PolygonSpawnPositions(dragPoints, s.Config)
+	case SpawnSoftBody:
+		return physics.SoftBodyPositions(dragStart, rl.Vector2Distance(dragStart, cursor), int(s.Config.SoftBodyParticles))
+	default:
+		return nil
+	}
+}
+
+func drawSpawnPreview(s *physics.Simulation) {
+	if !isDragging || activeObstacleTool != ObstacleNone || activeConstraintTool != ConstraintNone {
+		return
+	}
+
+	cursor := worldMousePosition()
+	outline := rl.DarkGray
+
+	start := toPixelsV(dragStart)
+	end := toPixelsV(cursor)
+
+	switch activeSpawnTool {
+	case SpawnRectangle:
+		rl.DrawRectangleLinesEx(utils.RectangleFromCorners(start, end), 1, outline)
+	case SpawnCircle, SpawnSoftBody:
+		rl.DrawCircleLines(int32(start.X), int32(start.Y), rl.Vector2Distance(start, end), outline)
+	case SpawnLine:
+		rl.DrawLineV(start, end, outline)
+	case SpawnPolygon, SpawnRigidBody:
+		drawDragPath(outline)
+	}
+
+	positions := spawnPreviewPositions(s, cursor)
+	for _, position := range positions {
+		center := toPixelsV(position)
+		rl.DrawCircleLines(int32(center.X), int32(center.Y), toPixels(physics.SpawnRadius(s.Config)), rl.Fade(outline, 0.5))
+	}
+
+	count := fmt.Sprintf("%d units", len(positions))
+	offset := 15 / camera.Zoom
+	rl.DrawText(count, int32(end.X+offset), int32(end.Y+offset), int32(20/camera.Zoom), rl.Black)
+}
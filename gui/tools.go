@@ -0,0 +1,67 @@
+package gui
+
+import (
+	"github.com/alexanderi96/go-fluid-simulator/physics"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+var (
+	isDragging bool
+	dragStart  rl.Vector2
+	dragPoints []rl.Vector2
+)
+
+func handleTools(s *physics.Simulation) {
+	if activeInteractionTool == physics.InteractionNone {
+		s.Interaction = physics.Interaction{}
+	}
+
+	switch {
+	case activeObstacleTool != ObstacleNone:
+		handleObstacleTool(s)
+	case activeConstraintTool != ConstraintNone:
+		handleConstraintTool(s)
+	case activeFieldTool != FieldNone:
+		handleFieldTool(s)
+	case activeInteractionTool != physics.InteractionNone:
+		handleInteractionTool(s)
+	default:
+		handleSpawnTool(s)
+	}
+}
+
+func clearTools() {
+	activeObstacleTool = ObstacleNone
+	activeConstraintTool = ConstraintNone
+	activeFieldTool = FieldNone
+	activeInteractionTool = physics.InteractionNone
+	isDragging = false
+}
+
+func startDrag(position rl.Vector2) {
+	isDragging = true
+	dragStart = position
+	dragPoints = []rl.Vector2{position}
+}
+
+func updateDrag(position rl.Vector2, minPointDistance float32) bool {
+	if toPixels(rl.Vector2Distance(dragPoints[len(dragPoints)-1], position)) >= minPointDistance {
+		dragPoints = append(dragPoints, position)
+	}
+
+	if rl.IsMouseButtonReleased(rl.MouseLeftButton) {
+		isDragging = false
+		return true
+	}
+
+	return false
+}
+
+func drawDragPath(color rl.Color) {
+	if len(dragPoints) > 1 {
+		points := toPixelsPolygon(dragPoints)
+		rl.DrawLineStrip(points, int32(len(points)), color)
+		rl.DrawLineV(points[len(points)-1], points[0], rl.Gray)
+	}
+}
@@ -0,0 +1,44 @@
+package gui
+
+import (
+	"github.com/alexanderi96/go-fluid-simulator/physics"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// metresPerPixel is ScaleFactor, the metres a pixel stands for at zoom 1.
+var metresPerPixel float32 = 1
+
+func syncScale(s *physics.Simulation) {
+	if s.Config.ScaleFactor > 0 {
+		metresPerPixel = s.Config.ScaleFactor
+	}
+}
+
+func toPixels(metres float32) float32 {
+	return metres / metresPerPixel
+}
+
+func toPixelsV(v rl.Vector2) rl.Vector2 {
+	return rl.Vector2Scale(v, 1/metresPerPixel)
+}
+
+func toMetres(pixels float32) float32 {
+	return pixels * metresPerPixel
+}
+
+func toMetresV(v rl.Vector2) rl.Vector2 {
+	return rl.Vector2Scale(v, metresPerPixel)
+}
+
+func toPixelsPolygon(points []rl.Vector2) []rl.Vector2 {
+	pixels := make([]rl.Vector2, len(points))
+	for i, point := range points {
+		pixels[i] = toPixelsV(point)
+	}
+	return pixels
+}
+
+func toPixelsRectangle(r rl.Rectangle) rl.Rectangle {
+	return rl.Rectangle{X: toPixels(r.X), Y: toPixels(r.Y), Width: toPixels(r.Width), Height: toPixels(r.Height)}
+}
@@ -49,21 +49,7 @@ func main() {
 	for !rl.WindowShouldClose() {
 		simulation.Config.UpdateWindowSettings()
 
-		if rl.IsKeyPressed(rl.KeyR) {
-			simulation.Reset()
-		} else if rl.IsKeyPressed(rl.KeySpace) {
-			simulation.IsPause = !simulation.IsPause
-		} else if rl.IsMouseButtonPressed(rl.MouseLeftButton) {
-			mousePosition := rl.GetMousePosition()
-			if mousePosition.X > 0 && mousePosition.X < float32(simulation.Config.WindowWidth-simulation.Config.SidebarWidth) && mousePosition.Y > 0 && mousePosition.Y < float32(simulation.Config.WindowHeight) {
-				simulation.NewFluidAtPosition(mousePosition)
-			}
-		} else if rl.IsMouseButtonPressed(rl.MouseRightButton) {
-			mousePosition := rl.GetMousePosition()
-			if mousePosition.X > 0 && mousePosition.X < float32(simulation.Config.WindowWidth-simulation.Config.SidebarWidth) && mousePosition.Y > 0 && mousePosition.Y < float32(simulation.Config.WindowHeight) {
-				simulation.NewFluidWithVelocity(mousePosition)
-			}
-		}
+		gui.HandleInput(simulation)
 
 		if !simulation.IsPause {
 			if err := simulation.Update(); err != nil {
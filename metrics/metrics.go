@@ -19,6 +19,14 @@ type Metrics struct {
 	ActiveThreads uint32
 	DiskUsage     uint32
 	NetworkUsage  uint32
+
+	// Substeps steps of Timestep seconds were taken in the last frame.
+	Timestep float32
+	Substeps int
+
+	// Residual overlaps after the last solver run, in metres.
+	MaxResidualOverlap  float32
+	MeanResidualOverlap float32
 }
 
 func New() *Metrics {
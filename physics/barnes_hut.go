@@ -0,0 +1,171 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-fluid-simulator/utils"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// maxQuadDepth lets units on top of each other share a leaf.
+const maxQuadDepth = 32
+
+type quadNode struct {
+	bounds       rl.Rectangle
+	mass         float32
+	centerOfMass rl.Vector2
+	units        []*Unit
+	children     [4]*quadNode
+	divided      bool
+}
+
+func newGravityTree(units []*Unit) *quadNode {
+	if len(units) == 0 {
+		return nil
+	}
+
+	positions := make([]rl.Vector2, 0, len(units))
+	for _, unit := range units {
+		positions = append(positions, unit.Position)
+	}
+	bounds := utils.PolygonBounds(positions)
+	size := float32(math.Max(float64(bounds.Width), float64(bounds.Height))) + 1e-3
+
+	root := &quadNode{bounds: rl.Rectangle{X: bounds.X, Y: bounds.Y, Width: size, Height: size}}
+	for _, unit := range units {
+		root.insert(unit, 0)
+	}
+	return root
+}
+
+func (n *quadNode) insert(u *Unit, depth int) {
+	weighted := rl.Vector2Add(rl.Vector2Scale(n.centerOfMass, n.mass), rl.Vector2Scale(u.Position, u.Mass))
+	n.mass += u.Mass
+	if n.mass > 0 {
+		n.centerOfMass = rl.Vector2Scale(weighted, 1/n.mass)
+	}
+
+	if !n.divided {
+		if len(n.units) == 0 || depth >= maxQuadDepth {
+			n.units = append(n.units, u)
+			return
+		}
+
+		n.divide()
+		for _, unit := range n.units {
+			n.child(unit.Position).insert(unit, depth+1)
+		}
+		n.units = nil
+	}
+
+	n.child(u.Position).insert(u, depth+1)
+}
+
+func (n *quadNode) divide() {
+	half := n.bounds.Width / 2
+	for i := range n.children {
+		n.children[i] = &quadNode{bounds: rl.Rectangle{
+			X:      n.bounds.X + float32(i%2)*half,
+			Y:      n.bounds.Y + float32(i/2)*half,
+			Width:  half,
+			Height: half,
+		}}
+	}
+	n.divided = true
+}
+
+func (n *quadNode) child(position rl.Vector2) *quadNode {
+	index := 0
+	if position.X >= n.bounds.X+n.bounds.Width/2 {
+		index++
+	}
+	if position.Y >= n.bounds.Y+n.bounds.Height/2 {
+		index += 2
+	}
+	return n.children[index]
+}
+
+// Squares that look smaller than theta from position act as a whole.
+func (n *quadNode) visit(position rl.Vector2, self *Unit, theta float32, fn func(mass float32, center rl.Vector2)) {
+	if n == nil || n.mass == 0 {
+		return
+	}
+
+	if !n.divided {
+		for _, unit := range n.units {
+			if unit != self {
+				fn(unit.Mass, unit.Position)
+			}
+		}
+		return
+	}
+
+	distance := rl.Vector2Distance(position, n.centerOfMass)
+	if distance > 0 && n.bounds.Width/distance < theta && !rl.CheckCollisionPointRec(position, n.bounds) {
+		fn(n.mass, n.centerOfMass)
+		return
+	}
+
+	for _, child := range n.children {
+		child.visit(position, self, theta, fn)
+	}
+}
+
+func (n *quadNode) cells(bounds []rl.Rectangle) []rl.Rectangle {
+	if n == nil {
+		return bounds
+	}
+	bounds = append(bounds, n.bounds)
+	if n.divided {
+		for _, child := range n.children {
+			bounds = child.cells(bounds)
+		}
+	}
+	return bounds
+}
+
+// Softened as G·m/(r² + ε²). Periodic edges are ignored.
+func (s *Simulation) applySelfGravity() {
+	s.gravityTree = newGravityTree(s.Fluid)
+
+	g := s.Config.GravitationalConstant
+	softening := s.Config.GravitySoftening * s.Config.GravitySoftening
+
+	for _, unit := range s.Fluid {
+		acceleration := rl.Vector2{}
+		s.gravityTree.visit(unit.Position, unit, s.Config.BarnesHutTheta, func(mass float32, center rl.Vector2) {
+			delta := rl.Vector2Subtract(center, unit.Position)
+			distanceSquared := rl.Vector2LenSqr(delta) + softening
+			if distanceSquared == 0 {
+				return
+			}
+			scale := g * mass / (distanceSquared * float32(math.Sqrt(float64(distanceSquared))))
+			acceleration = rl.Vector2Add(acceleration, rl.Vector2Scale(delta, scale))
+		})
+		unit.accelerate(acceleration)
+	}
+}
+
+func (s *Simulation) GravitationalPotentialEnergy() float32 {
+	tree := newGravityTree(s.Fluid)
+
+	g := s.Config.GravitationalConstant
+	softening := s.Config.GravitySoftening * s.Config.GravitySoftening
+
+	energy := float32(0)
+	for _, unit := range s.Fluid {
+		tree.visit(unit.Position, unit, s.Config.BarnesHutTheta, func(mass float32, center rl.Vector2) {
+			distance := float32(math.Sqrt(float64(rl.Vector2LenSqr(rl.Vector2Subtract(center, unit.Position)) + softening)))
+			if distance > 0 {
+				energy -= g * mass * unit.Mass / distance
+			}
+		})
+	}
+
+	// Every pair was counted from both ends.
+	return energy / 2
+}
+
+func (s *Simulation) GravityCells() []rl.Rectangle {
+	return s.gravityTree.cells(nil)
+}
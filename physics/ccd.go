@@ -0,0 +1,264 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-fluid-simulator/config"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// After maxSweepImpacts a unit is stopped at the last one.
+const maxSweepImpacts = 4
+
+// time is a fraction of the swept motion, and unit is nil for walls and
+// obstacles.
+type impact struct {
+	time        float32
+	normal      rl.Vector2
+	restitution float32
+	unit        *Unit
+}
+
+// The units the fast ones run into are taken where they are at the end of the
+// step.
+func (s *Simulation) sweepUnits(starts []rl.Vector2) {
+	var grid *neighbourGrid
+	maxRadius := s.maxUnitRadius()
+
+	for i, unit := range s.Fluid {
+		motion := rl.Vector2Subtract(unit.Position, starts[i])
+		if rl.Vector2Length(motion) <= s.Config.CCDThreshold*unit.Radius {
+			continue
+		}
+
+		if grid == nil {
+			grid = s.newNeighbourGrid(2 * maxRadius)
+		}
+		s.sweepUnit(unit, starts[i], motion, grid, maxRadius)
+	}
+}
+
+func (s *Simulation) sweepUnit(u *Unit, start, motion rl.Vector2, grid *neighbourGrid, maxRadius float32) {
+	velocity := u.GetVelocityWithVerlet()
+
+	for impacts := 0; ; impacts++ {
+		hit, ok := s.firstImpact(u, start, motion, grid, maxRadius)
+		if !ok {
+			break
+		}
+
+		start = rl.Vector2Add(start, rl.Vector2Scale(motion, hit.time))
+		if impacts == maxSweepImpacts {
+			motion = rl.Vector2{}
+			break
+		}
+		remaining := 1 - hit.time
+		motion = rl.Vector2Scale(motion, remaining)
+
+		if hit.unit != nil {
+			hit.unit.wake()
+			change := bounceOffUnit(u, hit.unit, hit.normal, velocity, hit.restitution)
+			velocity = rl.Vector2Add(velocity, change)
+			motion = rl.Vector2Add(motion, rl.Vector2Scale(change, remaining))
+		} else {
+			velocity = reflect(velocity, hit.normal, hit.restitution)
+			motion = reflect(motion, hit.normal, hit.restitution)
+		}
+	}
+
+	u.Position = rl.Vector2Add(start, motion)
+	u.PreviousPosition = rl.Vector2Subtract(u.Position, velocity)
+}
+
+func (s *Simulation) firstImpact(u *Unit, start, motion rl.Vector2, grid *neighbourGrid, maxRadius float32) (impact, bool) {
+	first := impact{time: 2}
+	consider := func(time float32, normal rl.Vector2, restitution float32, unit *Unit) {
+		if time < first.time {
+			first = impact{time: time, normal: normal, restitution: restitution, unit: unit}
+		}
+	}
+
+	if s.Config.ContainerShape == config.ContainerCircle {
+		center, radius := s.CircleContainer()
+		if time, ok := sweepInsideCircle(start, motion, center, radius-u.Radius); ok {
+			point := rl.Vector2Add(start, rl.Vector2Scale(motion, time))
+			consider(time, rl.Vector2Normalize(rl.Vector2Subtract(center, point)), s.Config.WallElasticity, nil)
+		}
+	} else {
+		if !s.Config.PeriodicX {
+			if time, side, ok := sweepWall(start.X, motion.X, u.Radius, s.Config.GameX, false, false); ok {
+				consider(time, rl.Vector2{X: side}, s.Config.WallElasticity, nil)
+			}
+		}
+		if !s.Config.PeriodicY {
+			if time, side, ok := sweepWall(start.Y, motion.Y, u.Radius, s.Config.GameY, s.Config.OpenTop, s.Config.OpenFloor); ok {
+				consider(time, rl.Vector2{Y: side}, s.Config.WallElasticity, nil)
+			}
+		}
+	}
+
+	for _, obstacle := range s.Obstacles {
+		if time, normal, ok := obstacle.sweep(start, motion, u.Radius); ok {
+			consider(time, normal, obstacle.restitution(s.Config.WallElasticity), nil)
+		}
+	}
+
+	if s.Config.InteractionMode != config.InteractionLennardJones {
+		end := rl.Vector2Add(start, motion)
+		grid.forEachAlong(start, end, u.Radius+maxRadius, func(other *Unit) {
+			if other == u || u.Body != nil && u.Body == other.Body {
+				return
+			}
+
+			center := rl.Vector2Subtract(start, s.separation(other.Position, start))
+			if time, normal, ok := sweepCircle(start, motion, center, u.Radius+other.Radius); ok {
+				restitution, ok := pairRestitution(u, other, s.Config)
+				if !ok {
+					restitution = (u.Elasticity + other.Elasticity) / 2
+				}
+				consider(time, normal, restitution, other)
+			}
+		})
+	}
+
+	return first, first.time <= 1
+}
+
+func reflect(velocity, normal rl.Vector2, restitution float32) rl.Vector2 {
+	normalSpeed := rl.Vector2DotProduct(velocity, normal)
+	if normalSpeed >= 0 {
+		return velocity
+	}
+	return rl.Vector2Subtract(velocity, rl.Vector2Scale(normal, (1+restitution)*normalSpeed))
+}
+
+// bounceOffUnit changes other's velocity and returns the change in u's.
+func bounceOffUnit(u, other *Unit, normal, velocity rl.Vector2, restitution float32) rl.Vector2 {
+	approach := rl.Vector2DotProduct(rl.Vector2Subtract(velocity, other.GetVelocityWithVerlet()), normal)
+	if approach >= 0 {
+		return rl.Vector2{}
+	}
+
+	impulse := -(1 + restitution) * approach / (1/u.Mass + 1/other.Mass)
+	other.PreviousPosition = rl.Vector2Add(other.PreviousPosition, rl.Vector2Scale(normal, impulse/other.Mass))
+	return rl.Vector2Scale(normal, impulse/u.Mass)
+}
+
+func sweepWall(position, motion, radius, size float32, openMin, openMax bool) (float32, float32, bool) {
+	if motion < 0 && !openMin && position-radius >= 0 && position+motion-radius < 0 {
+		return (position - radius) / -motion, 1, true
+	}
+	if motion > 0 && !openMax && position+radius <= size && position+motion+radius > size {
+		return (size - radius - position) / motion, -1, true
+	}
+	return 0, 0, false
+}
+
+// A point that starts within reach is left to the overlap resolution.
+func sweepCircle(position, motion, center rl.Vector2, reach float32) (float32, rl.Vector2, bool) {
+	offset := rl.Vector2Subtract(position, center)
+	a := rl.Vector2LenSqr(motion)
+	b := 2 * rl.Vector2DotProduct(offset, motion)
+	c := rl.Vector2LenSqr(offset) - reach*reach
+
+	if c < 0 || b >= 0 || a == 0 {
+		return 0, rl.Vector2{}, false
+	}
+
+	discriminant := b*b - 4*a*c
+	if discriminant < 0 {
+		return 0, rl.Vector2{}, false
+	}
+
+	time := (-b - float32(math.Sqrt(float64(discriminant)))) / (2 * a)
+	if time > 1 {
+		return 0, rl.Vector2{}, false
+	}
+
+	point := rl.Vector2Add(offset, rl.Vector2Scale(motion, time))
+	return time, rl.Vector2Normalize(point), true
+}
+
+func sweepInsideCircle(position, motion, center rl.Vector2, reach float32) (float32, bool) {
+	offset := rl.Vector2Subtract(position, center)
+	a := rl.Vector2LenSqr(motion)
+	b := 2 * rl.Vector2DotProduct(offset, motion)
+	c := rl.Vector2LenSqr(offset) - reach*reach
+
+	if c > 0 || a == 0 {
+		return 0, false
+	}
+
+	time := (-b + float32(math.Sqrt(float64(b*b-4*a*c)))) / (2 * a)
+	return time, time <= 1
+}
+
+func sweepSegment(position, motion, a, b rl.Vector2, radius float32) (float32, rl.Vector2, bool) {
+	edge := rl.Vector2Subtract(b, a)
+	length := rl.Vector2Length(edge)
+	if length == 0 {
+		return sweepCircle(position, motion, a, radius)
+	}
+
+	time, normal, ok := sweepCircle(position, motion, a, radius)
+	if endTime, endNormal, endOk := sweepCircle(position, motion, b, radius); endOk && (!ok || endTime < time) {
+		time, normal, ok = endTime, endNormal, true
+	}
+
+	side := rl.Vector2{X: -edge.Y / length, Y: edge.X / length}
+	distance := rl.Vector2DotProduct(rl.Vector2Subtract(position, a), side)
+	if distance < 0 {
+		side = rl.Vector2Negate(side)
+		distance = -distance
+	}
+
+	approach := rl.Vector2DotProduct(motion, side)
+	if approach < 0 && distance >= radius {
+		faceTime := (distance - radius) / -approach
+		point := rl.Vector2Add(position, rl.Vector2Scale(motion, faceTime))
+		along := rl.Vector2DotProduct(rl.Vector2Subtract(point, a), edge) / (length * length)
+
+		if faceTime <= 1 && along >= 0 && along <= 1 && (!ok || faceTime < time) {
+			time, normal, ok = faceTime, side, true
+		}
+	}
+
+	return time, normal, ok
+}
+
+func (o *Obstacle) sweep(position, motion rl.Vector2, radius float32) (float32, rl.Vector2, bool) {
+	if _, _, touching := o.contact(position, radius); touching {
+		return 0, rl.Vector2{}, false
+	}
+
+	points := o.WorldPoints()
+
+	switch o.Shape {
+	case ObstacleCircle:
+		return sweepCircle(position, motion, o.WorldCenter(), radius+o.Radius)
+
+	case ObstacleSegment:
+		if len(points) < 2 {
+			return 0, rl.Vector2{}, false
+		}
+		return sweepSegment(position, motion, points[0], points[1], radius)
+
+	case ObstaclePolygon:
+		if len(points) < 3 {
+			return 0, rl.Vector2{}, false
+		}
+
+		var time float32
+		var normal rl.Vector2
+		found := false
+		for i := range points {
+			edgeTime, edgeNormal, ok := sweepSegment(position, motion, points[i], points[(i+1)%len(points)], radius)
+			if ok && (!found || edgeTime < time) {
+				time, normal, found = edgeTime, edgeNormal, true
+			}
+		}
+		return time, normal, found
+	}
+
+	return 0, rl.Vector2{}, false
+}
@@ -0,0 +1,81 @@
+package physics
+
+import (
+	"bytes"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Fading out linearly at reach lets packed units settle at a slight overlap.
+func cohesionForce(gap, strength, reach float32) float32 {
+	if reach <= 0 || gap >= reach {
+		return 0
+	}
+	if gap < 0 {
+		gap = 0
+	}
+	return strength * (1 - gap/reach)
+}
+
+// Each pair is visited from both sides, so every visit only accelerates its own
+// unit.
+func (s *Simulation) applyCohesion(grid *neighbourGrid) {
+	reach := s.Config.CohesionRange
+
+	for _, unit := range s.Fluid {
+		if unit == nil {
+			continue
+		}
+
+		grid.forEachNear(unit.Position, func(other *Unit) {
+			if other == unit || (unit.Body != nil && unit.Body == other.Body) {
+				return
+			}
+
+			delta := s.separation(unit.Position, other.Position)
+			distance := rl.Vector2Length(delta)
+			if distance == 0 {
+				return
+			}
+
+			force := cohesionForce(distance-unit.Radius-other.Radius, pairCohesion(unit, other, s.Config), reach)
+			if force > 0 {
+				unit.accelerate(rl.Vector2Scale(delta, force/distance/unit.Mass))
+			}
+		})
+
+		for _, obstacle := range s.Obstacles {
+			normal, depth, ok := obstacle.contact(unit.Position, unit.Radius+reach)
+			if !ok {
+				continue
+			}
+
+			force := cohesionForce(reach-depth, cohesionOf(unit, s.Config), reach)
+			unit.accelerate(rl.Vector2Scale(normal, -force/unit.Mass))
+		}
+	}
+}
+
+func (s *Simulation) CohesionBonds() [][2]*Unit {
+	reach := s.Config.CohesionRange
+	grid := s.newNeighbourGrid(2*s.maxUnitRadius() + reach)
+
+	bonds := [][2]*Unit{}
+	for _, unit := range s.Fluid {
+		if unit == nil {
+			continue
+		}
+
+		grid.forEachNear(unit.Position, func(other *Unit) {
+			if bytes.Compare(unit.Id[:], other.Id[:]) >= 0 || (unit.Body != nil && unit.Body == other.Body) {
+				return
+			}
+
+			distance := rl.Vector2Length(s.separation(unit.Position, other.Position))
+			if cohesionForce(distance-unit.Radius-other.Radius, pairCohesion(unit, other, s.Config), reach) > 0 {
+				bonds = append(bonds, [2]*Unit{unit, other})
+			}
+		})
+	}
+	return bonds
+}
@@ -0,0 +1,158 @@
+package physics
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Stiffness is in N/m and Damping in N·s/m. A zero BreakStrain never breaks.
+type Constraint struct {
+	A           *Unit
+	B           *Unit
+	RestLength  float32
+	Stiffness   float32
+	Damping     float32
+	Rigid       bool
+	BreakStrain float32
+}
+
+func (s *Simulation) NewConstraint(a, b *Unit) *Constraint {
+	constraint := &Constraint{
+		A:           a,
+		B:           b,
+		RestLength:  rl.Vector2Length(s.separation(a.Position, b.Position)),
+		Stiffness:   s.Config.SpringStiffness,
+		Damping:     s.Config.SpringDamping,
+		Rigid:       s.Config.RigidConstraints,
+		BreakStrain: s.Config.ConstraintBreakStrain,
+	}
+
+	s.Constraints = append(s.Constraints, constraint)
+	return constraint
+}
+
+func (s *Simulation) Strain(c *Constraint) float32 {
+	if c.RestLength == 0 {
+		return 0
+	}
+	return (rl.Vector2Length(s.separation(c.A.Position, c.B.Position)) - c.RestLength) / c.RestLength
+}
+
+func (s *Simulation) LinkEnd(c *Constraint) rl.Vector2 {
+	return rl.Vector2Add(c.A.Position, s.separation(c.A.Position, c.B.Position))
+}
+
+func (s *Simulation) UnitAt(position rl.Vector2) *Unit {
+	for _, unit := range s.Fluid {
+		if distanceBetween(unit.Position, position) <= unit.Radius {
+			return unit
+		}
+	}
+	return nil
+}
+
+func (s *Simulation) NewChain(start, end rl.Vector2) {
+	positions := LineSpawnPositions(start, end, s.Config)
+	first := len(s.Fluid)
+	s.NewFluidAtPositions(positions)
+
+	for i := first + 1; i < len(s.Fluid); i++ {
+		s.NewConstraint(s.Fluid[i-1], s.Fluid[i])
+	}
+}
+
+// NewGrid links the diagonals too, so the cloth resists shearing.
+func (s *Simulation) NewGrid(rect rl.Rectangle) {
+	spacing := spawnSpacing(s.Config)
+	columns := int(rect.Width/spacing) + 1
+	rows := int(rect.Height/spacing) + 1
+
+	positions := RectangleSpawnPositions(rect, s.Config)
+	if len(positions) != columns*rows {
+		return
+	}
+
+	first := len(s.Fluid)
+	s.NewFluidAtPositions(positions)
+	at := func(row, column int) *Unit {
+		return s.Fluid[first+row*columns+column]
+	}
+
+	for row := 0; row < rows; row++ {
+		for column := 0; column < columns; column++ {
+			if column+1 < columns {
+				s.NewConstraint(at(row, column), at(row, column+1))
+			}
+			if row+1 < rows {
+				s.NewConstraint(at(row, column), at(row+1, column))
+			}
+			if column+1 < columns && row+1 < rows {
+				s.NewConstraint(at(row, column), at(row+1, column+1))
+				s.NewConstraint(at(row, column+1), at(row+1, column))
+			}
+		}
+	}
+}
+
+func (s *Simulation) applySpringForces(dt float32) {
+	for _, c := range s.Constraints {
+		if c.Rigid {
+			continue
+		}
+
+		delta := s.separation(c.A.Position, c.B.Position)
+		distance := rl.Vector2Length(delta)
+		if distance == 0 {
+			continue
+		}
+		normal := rl.Vector2Scale(delta, 1/distance)
+
+		relativeVelocity := rl.Vector2Subtract(c.B.Velocity(dt), c.A.Velocity(dt))
+		force := c.Stiffness*(distance-c.RestLength) + c.Damping*rl.Vector2DotProduct(relativeVelocity, normal)
+
+		c.A.accelerate(rl.Vector2Scale(normal, force/c.A.Mass))
+		c.B.accelerate(rl.Vector2Scale(normal, -force/c.B.Mass))
+	}
+}
+
+func (s *Simulation) solveRigidConstraints() {
+	for _, c := range s.Constraints {
+		if !c.Rigid {
+			continue
+		}
+
+		delta := s.separation(c.A.Position, c.B.Position)
+		distance := rl.Vector2Length(delta)
+		if distance == 0 {
+			continue
+		}
+
+		inverseMassA := 1 / c.A.Mass
+		inverseMassB := 1 / c.B.Mass
+		correction := rl.Vector2Scale(delta, (distance-c.RestLength)/distance/(inverseMassA+inverseMassB))
+
+		c.A.Position = rl.Vector2Add(c.A.Position, rl.Vector2Scale(correction, inverseMassA))
+		c.B.Position = rl.Vector2Subtract(c.B.Position, rl.Vector2Scale(correction, inverseMassB))
+	}
+}
+
+func (s *Simulation) breakConstraints() {
+	remaining := s.Constraints[:0]
+	for _, c := range s.Constraints {
+		if c.BreakStrain <= 0 || float32(math.Abs(float64(s.Strain(c)))) <= c.BreakStrain {
+			remaining = append(remaining, c)
+		}
+	}
+	s.Constraints = remaining
+}
+
+func (s *Simulation) removeConstraintsOf(units map[*Unit]bool) {
+	remaining := s.Constraints[:0]
+	for _, c := range s.Constraints {
+		if !units[c.A] && !units[c.B] {
+			remaining = append(remaining, c)
+		}
+	}
+	s.Constraints = remaining
+}
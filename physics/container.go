@@ -0,0 +1,123 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-fluid-simulator/config"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// separation is the shortest vector from a to b, across periodic edges.
+func (s *Simulation) separation(a, b rl.Vector2) rl.Vector2 {
+	delta := rl.Vector2Subtract(b, a)
+
+	if s.Config.ContainerShape != config.ContainerCircle {
+		if s.Config.PeriodicX {
+			delta.X = wrapDelta(delta.X, s.Config.GameX)
+		}
+		if s.Config.PeriodicY {
+			delta.Y = wrapDelta(delta.Y, s.Config.GameY)
+		}
+	}
+
+	return delta
+}
+
+func wrapDelta(delta, size float32) float32 {
+	if delta > size/2 {
+		return delta - size
+	} else if delta < -size/2 {
+		return delta + size
+	}
+	return delta
+}
+
+// wrap returns the shift so the previous position can follow it.
+func wrap(value, size float32) float32 {
+	if value < 0 || value >= size {
+		return float32(math.Floor(float64(value/size))) * -size
+	}
+	return 0
+}
+
+func (s *Simulation) CircleContainer() (rl.Vector2, float32) {
+	center := rl.Vector2{X: s.Config.GameX / 2, Y: s.Config.GameY / 2}
+	radius := float32(math.Min(float64(s.Config.GameX), float64(s.Config.GameY))) / 2
+	return center, radius
+}
+
+func (s *Simulation) applyContainer(u *Unit) {
+	if s.Config.ContainerShape == config.ContainerCircle {
+		s.checkCircleContainerCollisionVerlet(u)
+		return
+	}
+
+	if s.Config.PeriodicX {
+		shift := wrap(u.Position.X, s.Config.GameX)
+		u.Position.X += shift
+		u.PreviousPosition.X += shift
+	}
+	if s.Config.PeriodicY {
+		shift := wrap(u.Position.Y, s.Config.GameY)
+		u.Position.Y += shift
+		u.PreviousPosition.Y += shift
+	}
+
+	u.checkWallCollisionVerlet(s.Config, s.Metrics.Timestep)
+}
+
+func (s *Simulation) checkCircleContainerCollisionVerlet(u *Unit) {
+	center, radius := s.CircleContainer()
+
+	fromCenter := rl.Vector2Subtract(u.Position, center)
+	distance := rl.Vector2Length(fromCenter)
+	limit := radius - u.Radius
+
+	if distance <= limit || distance == 0 {
+		return
+	}
+
+	normal := rl.Vector2Scale(fromCenter, -1/distance)
+	velocity := u.GetVelocityWithVerlet()
+	depth := distance - limit
+
+	u.Position = rl.Vector2Add(center, rl.Vector2Scale(fromCenter, limit/distance))
+
+	normalSpeed := rl.Vector2DotProduct(velocity, normal)
+	if normalSpeed < 0 {
+		velocity = rl.Vector2Subtract(velocity, rl.Vector2Scale(normal, (1+s.Config.WallElasticity)*normalSpeed))
+	}
+
+	u.PreviousPosition = rl.Vector2Subtract(u.Position, velocity)
+
+	u.applyWallFriction(normal, rl.Vector2{}, depth, s.Config.WallFriction, s.Metrics.Timestep)
+}
+
+func (s *Simulation) removeEscapedUnits() {
+	if !s.Config.OpenFloor || s.Config.PeriodicY || s.Config.ContainerShape == config.ContainerCircle {
+		return
+	}
+
+	floor := s.Config.GameY
+	escaped := map[*Unit]bool{}
+	remaining := s.Fluid[:0]
+	for _, unit := range s.Fluid {
+		if unit.Position.Y-unit.Radius <= floor {
+			remaining = append(remaining, unit)
+		} else {
+			escaped[unit] = true
+		}
+	}
+	s.Fluid = remaining
+
+	if len(escaped) > 0 {
+		s.removeConstraintsOf(escaped)
+		s.removeSoftBodiesOf(escaped)
+		s.removeRigidBodiesOf(escaped)
+		s.wakeResting(escaped)
+
+		if escaped[s.Interaction.Unit] {
+			s.Interaction = Interaction{}
+		}
+	}
+}
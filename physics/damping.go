@@ -0,0 +1,78 @@
+package physics
+
+import (
+	"github.com/alexanderi96/go-fluid-simulator/config"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const xsphRange = 1.5
+
+// applyDrag is implicit, so large coefficients stop units instead of reversing
+// them.
+func (u *Unit) applyDrag(cfg *config.Config, dt float32) {
+	if dt <= 0 {
+		return
+	}
+
+	velocity := u.GetVelocityWithVerlet()
+	factor := float32(1)
+
+	if cfg.ApplyLinearDrag {
+		factor /= 1 + cfg.LinearDrag*dt
+	}
+	if cfg.ApplyAirDrag {
+		factor /= 1 + cfg.AirDrag*u.Radius*rl.Vector2Length(velocity)/u.Mass
+	}
+
+	u.PreviousPosition = rl.Vector2Subtract(u.Position, rl.Vector2Scale(velocity, factor))
+	u.AngularVelocity *= factor
+}
+
+// smoothVelocities is XSPH, v += ε·Σw(vj − v)/Σw, with w falling linearly to
+// zero at xsphRange times the radii sum.
+func (s *Simulation) smoothVelocities() {
+	factor := rl.Clamp(s.Config.XSPHSmoothing, 0, 1)
+	if factor == 0 {
+		return
+	}
+
+	grid := s.newNeighbourGrid(2 * xsphRange * s.maxUnitRadius())
+	smoothed := make([]rl.Vector2, len(s.Fluid))
+
+	for i, unit := range s.Fluid {
+		if unit == nil {
+			continue
+		}
+
+		velocity := unit.GetVelocityWithVerlet()
+		sum := rl.Vector2{}
+		weights := float32(0)
+
+		grid.forEachNear(unit.Position, func(other *Unit) {
+			if other == unit {
+				return
+			}
+
+			reach := xsphRange * (unit.Radius + other.Radius)
+			distance := rl.Vector2Length(s.separation(unit.Position, other.Position))
+			if distance >= reach {
+				return
+			}
+
+			weight := 1 - distance/reach
+			sum = rl.Vector2Add(sum, rl.Vector2Scale(rl.Vector2Subtract(other.GetVelocityWithVerlet(), velocity), weight))
+			weights += weight
+		})
+
+		smoothed[i] = velocity
+		if weights > 0 {
+			smoothed[i] = rl.Vector2Add(velocity, rl.Vector2Scale(sum, factor/weights))
+		}
+	}
+
+	for i, unit := range s.Fluid {
+		if unit != nil && !unit.Asleep {
+			unit.PreviousPosition = rl.Vector2Subtract(unit.Position, smoothed[i])
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package physics
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Velocity is in m/s, whereas GetVelocityWithVerlet is the displacement per step.
+func (u *Unit) Velocity(dt float32) rl.Vector2 {
+	if dt <= 0 {
+		return rl.Vector2{}
+	}
+	return rl.Vector2Scale(u.GetVelocityWithVerlet(), 1/dt)
+}
+
+func (u *Unit) KineticEnergy(dt float32) float32 {
+	velocity := u.Velocity(dt)
+	return 0.5 * u.Mass * rl.Vector2LenSqr(velocity)
+}
+
+func (u *Unit3D) KineticEnergy(dt float32) float32 {
+	if dt <= 0 {
+		return 0
+	}
+	velocity := rl.Vector3Scale(u.GetVelocityWithVerlet(), 1/dt)
+	return 0.5 * u.Mass * rl.Vector3DotProduct(velocity, velocity)
+}
+
+func (s *Simulation) KineticEnergy() float32 {
+	energy := float32(0)
+	for _, unit := range s.Fluid {
+		energy += unit.KineticEnergy(s.Metrics.Timestep)
+	}
+	for _, unit := range s.Fluid3D {
+		energy += unit.KineticEnergy(s.Metrics.Timestep)
+	}
+	return energy
+}
+
+// Drift is relative to the total when units were last added or removed.
+type EnergyDiagnostics struct {
+	Kinetic   float32
+	Potential float32
+	Total     float32
+	Reference float32
+	Drift     float32
+
+	units int
+}
+
+func (s *Simulation) ExternalPotentialEnergy() float32 {
+	if !s.Config.ApplyGravity {
+		return 0
+	}
+
+	energy := float32(0)
+	for _, unit := range s.Fluid {
+		energy -= unit.Mass * rl.Vector2DotProduct(s.Config.Gravity, unit.Position)
+	}
+	return energy
+}
+
+func (s *Simulation) ResetEnergyReference() {
+	s.Energy.units = -1
+}
+
+func (s *Simulation) updateEnergyDiagnostics() {
+	e := &s.Energy
+	e.Kinetic = s.KineticEnergy()
+	e.Potential = s.GravitationalPotentialEnergy() + s.ExternalPotentialEnergy()
+	e.Total = e.Kinetic + e.Potential
+
+	if e.units != len(s.Fluid) {
+		e.units = len(s.Fluid)
+		e.Reference = e.Total
+	}
+
+	e.Drift = 0
+	if e.Reference != 0 {
+		e.Drift = (e.Total - e.Reference) / float32(math.Abs(float64(e.Reference)))
+	}
+}
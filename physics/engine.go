@@ -2,6 +2,7 @@ package physics
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/alexanderi96/go-fluid-simulator/config"
 	"github.com/alexanderi96/go-fluid-simulator/metrics"
@@ -9,20 +10,52 @@ import (
 )
 
 type Simulation struct {
-	Fluid   []*Unit
-	Metrics *metrics.Metrics
-	Config  *config.Config
-	IsPause bool
+	Fluid       []*Unit
+	Fluid3D     []*Unit3D
+	Obstacles   []*Obstacle
+	Fields      []ForceField
+	Constraints []*Constraint
+	SoftBodies  []*SoftBody
+	RigidBodies []*RigidBody
+	Interaction Interaction
+	Energy      EnergyDiagnostics
+	Metrics     *metrics.Metrics
+	Config      *config.Config
+	IsPause     bool
+	Time        float32
+
+	gravityTree    *quadNode
+	restingGravity rl.Vector2
+	spawned        chan spawnedUnit
 }
 
+const spawnBuffer = 64
+
 func NewSimulation(config *config.Config) (*Simulation, error) {
 	config.UpdateWindowSettings()
 
 	sim := &Simulation{
-		Fluid:   make([]*Unit, 0, config.ParticleNumber),
-		Metrics: &metrics.Metrics{},
-		Config:  config,
-		IsPause: false,
+		Fluid:     make([]*Unit, 0, config.ParticleNumber),
+		Obstacles: []*Obstacle{},
+		Metrics:   &metrics.Metrics{},
+		Config:    config,
+		IsPause:   false,
+	}
+
+	for _, boundary := range config.MovingBoundaries {
+		obstacle, err := newBoundaryObstacle(boundary)
+		if err != nil {
+			return nil, err
+		}
+		sim.AddObstacle(obstacle)
+	}
+
+	if config.SceneFile != "" {
+		if _, err := os.Stat(config.SceneFile); err == nil {
+			if err := sim.LoadScene(config.SceneFile); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return sim, nil
@@ -30,6 +63,14 @@ func NewSimulation(config *config.Config) (*Simulation, error) {
 
 func (s *Simulation) Reset() {
 	s.Fluid = []*Unit{}
+	s.Fluid3D = []*Unit3D{}
+	s.Constraints = []*Constraint{}
+	s.SoftBodies = []*SoftBody{}
+	s.RigidBodies = []*RigidBody{}
+	s.Interaction = Interaction{}
+	s.Energy = EnergyDiagnostics{}
+	s.gravityTree = nil
+	s.Time = 0
 }
 
 func (s *Simulation) NewFluidAtPosition(position rl.Vector2) {
@@ -37,7 +78,31 @@ func (s *Simulation) NewFluidAtPosition(position rl.Vector2) {
 }
 
 func (s *Simulation) NewFluidWithVelocity(position rl.Vector2) {
-	go spawnUnitsWithVelocity(&s.Fluid, position, s.Config)
+	if s.spawned == nil {
+		s.spawned = make(chan spawnedUnit, spawnBuffer)
+	}
+
+	units := make([]*Unit, 0, s.Config.ParticleNumber)
+	for i := 0; i < int(s.Config.ParticleNumber); i++ {
+		unit := NewUnitWithProperties(s.Config)
+		unit.Position = position
+		units = append(units, unit)
+	}
+
+	velocity := calculateInitialVelocity(position, s.Config.GameX, s.Config.GameY, s.Config.InitialSpeedMax)
+	go spawnUnitsWithVelocity(s.spawned, units, velocity)
+}
+
+func (s *Simulation) addSpawnedUnits() {
+	for {
+		select {
+		case spawned := <-s.spawned:
+			spawned.unit.PreviousPosition = rl.Vector2Subtract(spawned.unit.Position, rl.Vector2Scale(spawned.velocity, s.Metrics.Timestep))
+			s.Fluid = append(s.Fluid, spawned.unit)
+		default:
+			return
+		}
+	}
 }
 
 func (s *Simulation) Update() error {
@@ -45,8 +110,24 @@ func (s *Simulation) Update() error {
 
 	if s.Config.UseExperimentalQuadtree {
 		return fmt.Errorf("quadtree not implemented yet")
-	} else {
-		return s.UpdateWithVerletIntegration()
 	}
 
+	s.addSpawnedUnits()
+
+	step := s.UpdateWithVerletIntegration
+	if s.Config.Mode3D {
+		step = s.UpdateWithVerletIntegration3D
+	}
+
+	dt, substeps := s.chooseTimestep(s.Metrics.Frametime)
+	s.setTimestep(dt)
+	s.Metrics.Substeps = substeps
+
+	for i := 0; i < substeps; i++ {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+
 }
@@ -0,0 +1,50 @@
+package physics
+
+import (
+	"encoding/csv"
+	"os"
+	"strconv"
+)
+
+func (s *Simulation) ExportCSV(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+
+	header := []string{"id", "x_m", "y_m", "vx_m_s", "vy_m_s", "radius_m", "mass_kg", "kinetic_energy_j", "temperature_k", "material"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	dt := s.Metrics.Timestep
+	for _, unit := range s.Fluid {
+		velocity := unit.Velocity(dt)
+
+		record := []string{
+			unit.Id.String(),
+			formatFloat(unit.Position.X),
+			formatFloat(unit.Position.Y),
+			formatFloat(velocity.X),
+			formatFloat(velocity.Y),
+			formatFloat(unit.Radius),
+			formatFloat(unit.Mass),
+			formatFloat(unit.KineticEnergy(dt)),
+			formatFloat(unit.Temperature),
+			unit.MaterialName(),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatFloat(value float32) string {
+	return strconv.FormatFloat(float64(value), 'g', -1, 32)
+}
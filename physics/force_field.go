@@ -0,0 +1,229 @@
+package physics
+
+import (
+	"fmt"
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type ForceField interface {
+	Acceleration(position rl.Vector2, t float32) rl.Vector2
+	Anchor() rl.Vector2
+	MoveTo(position rl.Vector2)
+}
+
+type Falloff int
+
+const (
+	FalloffInverseSquare Falloff = iota
+	FalloffLinear
+)
+
+var falloffNames = map[Falloff]string{
+	FalloffInverseSquare: "inverse_square",
+	FalloffLinear:        "linear",
+}
+
+func (f Falloff) MarshalText() ([]byte, error) {
+	name, ok := falloffNames[f]
+	if !ok {
+		return nil, fmt.Errorf("unknown falloff %d", f)
+	}
+	return []byte(name), nil
+}
+
+func (f *Falloff) UnmarshalText(text []byte) error {
+	for falloff, name := range falloffNames {
+		if name == string(text) {
+			*f = falloff
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown falloff %q", text)
+}
+
+const (
+	turbulenceScale     = 0.5
+	turbulenceFrequency = 0.5
+)
+
+// With an inverse-square falloff Strength is reached at Radius and held inside
+// it; with a linear one it is reached at the centre and fades out at Radius.
+type PointField struct {
+	Center   rl.Vector2 `toml:"center"`
+	Strength float32    `toml:"strength"`
+	Radius   float32    `toml:"radius"`
+	Falloff  Falloff    `toml:"falloff"`
+}
+
+// A positive Strength swirls clockwise on screen.
+type Vortex struct {
+	Center   rl.Vector2 `toml:"center"`
+	Strength float32    `toml:"strength"`
+	Radius   float32    `toml:"radius"`
+}
+
+type Wind struct {
+	Region rl.Rectangle `toml:"region"`
+	Blow   rl.Vector2   `toml:"blow"`
+}
+
+type Turbulence struct {
+	Region    rl.Rectangle `toml:"region"`
+	Strength  float32      `toml:"strength"`
+	Scale     float32      `toml:"scale"`
+	Frequency float32      `toml:"frequency"`
+}
+
+func NewPointField(center rl.Vector2, strength, radius float32, falloff Falloff) *PointField {
+	return &PointField{Center: center, Strength: strength, Radius: radius, Falloff: falloff}
+}
+
+func NewVortex(center rl.Vector2, strength, radius float32) *Vortex {
+	return &Vortex{Center: center, Strength: strength, Radius: radius}
+}
+
+func NewWind(region rl.Rectangle, blow rl.Vector2) *Wind {
+	return &Wind{Region: region, Blow: blow}
+}
+
+func NewTurbulence(region rl.Rectangle, strength float32) *Turbulence {
+	return &Turbulence{Region: region, Strength: strength, Scale: turbulenceScale, Frequency: turbulenceFrequency}
+}
+
+func (f *PointField) Acceleration(position rl.Vector2, t float32) rl.Vector2 {
+	toCenter := rl.Vector2Subtract(f.Center, position)
+	distance := rl.Vector2Length(toCenter)
+	if distance == 0 || f.Radius <= 0 {
+		return rl.Vector2{}
+	}
+
+	var magnitude float32
+	switch f.Falloff {
+	case FalloffLinear:
+		if distance >= f.Radius {
+			return rl.Vector2{}
+		}
+		magnitude = f.Strength * (1 - distance/f.Radius)
+	default:
+		ratio := f.Radius / float32(math.Max(float64(distance), float64(f.Radius)))
+		magnitude = f.Strength * ratio * ratio
+	}
+
+	return rl.Vector2Scale(toCenter, magnitude/distance)
+}
+
+func (f *PointField) Anchor() rl.Vector2 { return f.Center }
+
+func (f *PointField) MoveTo(position rl.Vector2) { f.Center = position }
+
+func (v *Vortex) Acceleration(position rl.Vector2, t float32) rl.Vector2 {
+	fromCenter := rl.Vector2Subtract(position, v.Center)
+	distance := rl.Vector2Length(fromCenter)
+	if distance == 0 || distance >= v.Radius {
+		return rl.Vector2{}
+	}
+
+	magnitude := v.Strength * (1 - distance/v.Radius)
+	return rl.Vector2Scale(rl.Vector2{X: -fromCenter.Y, Y: fromCenter.X}, magnitude/distance)
+}
+
+func (v *Vortex) Anchor() rl.Vector2 { return v.Center }
+
+func (v *Vortex) MoveTo(position rl.Vector2) { v.Center = position }
+
+func (w *Wind) Acceleration(position rl.Vector2, t float32) rl.Vector2 {
+	if !rl.CheckCollisionPointRec(position, w.Region) {
+		return rl.Vector2{}
+	}
+	return w.Blow
+}
+
+func (w *Wind) Anchor() rl.Vector2 { return regionCenter(w.Region) }
+
+func (w *Wind) MoveTo(position rl.Vector2) { w.Region = centerRegionAt(w.Region, position) }
+
+func (n *Turbulence) Acceleration(position rl.Vector2, t float32) rl.Vector2 {
+	if !rl.CheckCollisionPointRec(position, n.Region) || n.Scale <= 0 {
+		return rl.Vector2{}
+	}
+
+	x := position.X / n.Scale
+	y := position.Y / n.Scale
+	z := t * n.Frequency
+
+	return rl.Vector2{
+		X: n.Strength * valueNoise(x, y, z, 1),
+		Y: n.Strength * valueNoise(x, y, z, 2),
+	}
+}
+
+func (n *Turbulence) Anchor() rl.Vector2 { return regionCenter(n.Region) }
+
+func (n *Turbulence) MoveTo(position rl.Vector2) { n.Region = centerRegionAt(n.Region, position) }
+
+func regionCenter(region rl.Rectangle) rl.Vector2 {
+	return rl.Vector2{X: region.X + region.Width/2, Y: region.Y + region.Height/2}
+}
+
+func centerRegionAt(region rl.Rectangle, center rl.Vector2) rl.Rectangle {
+	region.X = center.X - region.Width/2
+	region.Y = center.Y - region.Height/2
+	return region
+}
+
+// valueNoise interpolates pseudo-random values in [-1, 1] on the integer
+// lattice.
+func valueNoise(x, y, z float32, seed uint32) float32 {
+	x0, y0, z0 := float32(math.Floor(float64(x))), float32(math.Floor(float64(y))), float32(math.Floor(float64(z)))
+	fx, fy, fz := smoothstep(x-x0), smoothstep(y-y0), smoothstep(z-z0)
+	ix, iy, iz := int32(x0), int32(y0), int32(z0)
+
+	lerp := func(a, b, t float32) float32 { return a + (b-a)*t }
+	corner := func(dx, dy, dz int32) float32 { return latticeValue(ix+dx, iy+dy, iz+dz, seed) }
+
+	return lerp(
+		lerp(lerp(corner(0, 0, 0), corner(1, 0, 0), fx), lerp(corner(0, 1, 0), corner(1, 1, 0), fx), fy),
+		lerp(lerp(corner(0, 0, 1), corner(1, 0, 1), fx), lerp(corner(0, 1, 1), corner(1, 1, 1), fx), fy),
+		fz,
+	)
+}
+
+func smoothstep(t float32) float32 {
+	return t * t * (3 - 2*t)
+}
+
+func latticeValue(x, y, z int32, seed uint32) float32 {
+	h := uint32(x)*374761393 + uint32(y)*668265263 + uint32(z)*2246822519 + seed*3266489917
+	h = (h ^ (h >> 13)) * 1274126177
+	h ^= h >> 16
+	return float32(h)/float32(math.MaxUint32)*2 - 1
+}
+
+func (s *Simulation) AddField(field ForceField) {
+	s.Fields = append(s.Fields, field)
+	s.WakeAll()
+}
+
+func (s *Simulation) FieldAt(position rl.Vector2, reach float32) ForceField {
+	for _, field := range s.Fields {
+		if rl.Vector2Distance(field.Anchor(), position) <= reach {
+			return field
+		}
+	}
+	return nil
+}
+
+func (s *Simulation) ClearFields() {
+	s.Fields = []ForceField{}
+	s.WakeAll()
+}
+
+func (s *Simulation) fieldAcceleration(position rl.Vector2) rl.Vector2 {
+	acceleration := rl.Vector2{}
+	for _, field := range s.Fields {
+		acceleration = rl.Vector2Add(acceleration, field.Acceleration(position, s.Time))
+	}
+	return acceleration
+}
@@ -0,0 +1,63 @@
+package physics
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func (u *Unit) momentOfInertia() float32 {
+	return 0.5 * u.Mass * u.Radius * u.Radius
+}
+
+// Members of a rigid body turn with the body rather than on their own.
+func (u *Unit) spinResponse() float32 {
+	if u.Body != nil {
+		return 0
+	}
+	return u.Radius * u.Radius / u.momentOfInertia()
+}
+
+// The overlap that was just corrected stands in for the normal impulse.
+func applyContactFriction(a, b *Unit, normal rl.Vector2, overlap, friction, dt float32) {
+	if friction <= 0 || dt <= 0 {
+		return
+	}
+
+	tangent := rl.Vector2{X: -normal.Y, Y: normal.X}
+
+	relativeVelocity := rl.Vector2Subtract(b.GetVelocityWithVerlet(), a.GetVelocityWithVerlet())
+	slip := rl.Vector2DotProduct(relativeVelocity, tangent) - a.AngularVelocity*dt*a.Radius - b.AngularVelocity*dt*b.Radius
+
+	inverseMass := 1/a.Mass + 1/b.Mass
+	inverseInertia := a.spinResponse() + b.spinResponse()
+
+	impulse := -slip / (inverseMass + inverseInertia)
+	limit := friction * overlap / inverseMass
+	impulse = rl.Clamp(impulse, -limit, limit)
+
+	a.Position = rl.Vector2Subtract(a.Position, rl.Vector2Scale(tangent, impulse/a.Mass))
+	b.Position = rl.Vector2Add(b.Position, rl.Vector2Scale(tangent, impulse/b.Mass))
+
+	a.AngularVelocity -= impulse * a.spinResponse() / a.Radius / dt
+	b.AngularVelocity -= impulse * b.spinResponse() / b.Radius / dt
+}
+
+// surfaceVelocity is how far the surface moved during the step.
+func (u *Unit) applyWallFriction(normal, surfaceVelocity rl.Vector2, depth, friction, dt float32) {
+	if friction <= 0 || dt <= 0 {
+		return
+	}
+
+	tangent := rl.Vector2{X: -normal.Y, Y: normal.X}
+
+	relativeVelocity := rl.Vector2Subtract(u.GetVelocityWithVerlet(), surfaceVelocity)
+	slip := rl.Vector2DotProduct(relativeVelocity, tangent) - u.AngularVelocity*dt*u.Radius
+
+	impulse := -slip / (1/u.Mass + u.spinResponse())
+	limit := friction * depth * u.Mass
+	impulse = rl.Clamp(impulse, -limit, limit)
+
+	// Moving the position rather than the previous one also takes back the
+	// slide that gravity just added, so units can rest on slopes.
+	u.Position = rl.Vector2Add(u.Position, rl.Vector2Scale(tangent, impulse/u.Mass))
+	u.AngularVelocity -= impulse * u.spinResponse() / u.Radius / dt
+}
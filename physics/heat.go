@@ -0,0 +1,50 @@
+package physics
+
+import (
+	"github.com/alexanderi96/go-fluid-simulator/config"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// exchangeHeat never goes past the shared equilibrium temperature, so stiff
+// settings cannot overshoot.
+func exchangeHeat(a, b *Unit, overlap float32, cfg *config.Config, dt float32) {
+	if cfg.SpecificHeat <= 0 {
+		return
+	}
+
+	capacityA := a.Mass * cfg.SpecificHeat
+	capacityB := b.Mass * cfg.SpecificHeat
+	equilibrium := (capacityA*a.Temperature + capacityB*b.Temperature) / (capacityA + capacityB)
+
+	fraction := rl.Clamp(cfg.Conductivity*overlap*dt*(1/capacityA+1/capacityB), 0, 1)
+	a.Temperature += (equilibrium - a.Temperature) * fraction
+	b.Temperature += (equilibrium - b.Temperature) * fraction
+}
+
+// wallContactTolerance is a fraction of the unit's radius.
+const wallContactTolerance = 0.1
+
+// The side walls are insulating.
+func (s *Simulation) exchangeWallHeat(u *Unit, dt float32) {
+	cfg := s.Config
+	if cfg.SpecificHeat <= 0 || cfg.ContainerShape == config.ContainerCircle || cfg.PeriodicY {
+		return
+	}
+
+	capacity := u.Mass * cfg.SpecificHeat
+	fraction := rl.Clamp(cfg.WallConductivity*dt/capacity, 0, 1)
+	tolerance := wallContactTolerance * u.Radius
+
+	if !cfg.OpenFloor && cfg.GameY-u.Position.Y-u.Radius <= tolerance {
+		u.Temperature += (cfg.FloorTemperature - u.Temperature) * fraction
+	}
+	if !cfg.OpenTop && u.Position.Y-u.Radius <= tolerance {
+		u.Temperature += (cfg.CeilingTemperature - u.Temperature) * fraction
+	}
+}
+
+// Thermal expansion lifts units warmer than the ambient and sinks colder ones.
+func (s *Simulation) buoyancyAcceleration(u *Unit) rl.Vector2 {
+	lift := s.Config.ThermalExpansion * (u.Temperature - s.Config.AmbientTemperature)
+	return rl.Vector2Scale(s.Config.Gravity, -lift)
+}
@@ -0,0 +1,116 @@
+package physics
+
+import (
+	"fmt"
+
+	"github.com/alexanderi96/go-fluid-simulator/config"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type accelerationFunc func(position, velocity rl.Vector2) rl.Vector2
+
+// The acceleration from other units is held fixed over the step, while gravity,
+// buoyancy, force fields and the mouse are re-evaluated.
+type Integrator interface {
+	Step(position, velocity rl.Vector2, acceleration accelerationFunc, dt float32) (rl.Vector2, rl.Vector2)
+}
+
+type PositionVerlet struct{}
+
+type VelocityVerlet struct{}
+
+type RK4 struct{}
+
+func (PositionVerlet) Step(position, velocity rl.Vector2, acceleration accelerationFunc, dt float32) (rl.Vector2, rl.Vector2) {
+	a := acceleration(position, velocity)
+	next := rl.Vector2Add(position, rl.Vector2Add(rl.Vector2Scale(velocity, dt), rl.Vector2Scale(a, dt*dt)))
+	return next, rl.Vector2Scale(rl.Vector2Subtract(next, position), 1/dt)
+}
+
+func (VelocityVerlet) Step(position, velocity rl.Vector2, acceleration accelerationFunc, dt float32) (rl.Vector2, rl.Vector2) {
+	a := acceleration(position, velocity)
+	next := rl.Vector2Add(position, rl.Vector2Add(rl.Vector2Scale(velocity, dt), rl.Vector2Scale(a, dt*dt/2)))
+
+	// The velocity at the end of the step is not known yet, so the forces
+	// that depend on it see the Euler prediction.
+	predicted := rl.Vector2Add(velocity, rl.Vector2Scale(a, dt))
+	nextA := acceleration(next, predicted)
+
+	return next, rl.Vector2Add(velocity, rl.Vector2Scale(rl.Vector2Add(a, nextA), dt/2))
+}
+
+func (RK4) Step(position, velocity rl.Vector2, acceleration accelerationFunc, dt float32) (rl.Vector2, rl.Vector2) {
+	k1x, k1v := velocity, acceleration(position, velocity)
+
+	x2 := rl.Vector2Add(position, rl.Vector2Scale(k1x, dt/2))
+	v2 := rl.Vector2Add(velocity, rl.Vector2Scale(k1v, dt/2))
+	k2x, k2v := v2, acceleration(x2, v2)
+
+	x3 := rl.Vector2Add(position, rl.Vector2Scale(k2x, dt/2))
+	v3 := rl.Vector2Add(velocity, rl.Vector2Scale(k2v, dt/2))
+	k3x, k3v := v3, acceleration(x3, v3)
+
+	x4 := rl.Vector2Add(position, rl.Vector2Scale(k3x, dt))
+	v4 := rl.Vector2Add(velocity, rl.Vector2Scale(k3v, dt))
+	k4x, k4v := v4, acceleration(x4, v4)
+
+	sum := func(k1, k2, k3, k4 rl.Vector2) rl.Vector2 {
+		return rl.Vector2Add(rl.Vector2Add(k1, rl.Vector2Scale(k2, 2)), rl.Vector2Add(rl.Vector2Scale(k3, 2), k4))
+	}
+
+	return rl.Vector2Add(position, rl.Vector2Scale(sum(k1x, k2x, k3x, k4x), dt/6)),
+		rl.Vector2Add(velocity, rl.Vector2Scale(sum(k1v, k2v, k3v, k4v), dt/6))
+}
+
+func NewIntegrator(name string) (Integrator, error) {
+	switch name {
+	case "", config.IntegratorVerlet, config.IntegratorEuler:
+		return PositionVerlet{}, nil
+	case config.IntegratorVelocityVerlet:
+		return VelocityVerlet{}, nil
+	case config.IntegratorRK4:
+		return RK4{}, nil
+	default:
+		return nil, fmt.Errorf("unknown integrator %q", name)
+	}
+}
+
+func (s *Simulation) localAcceleration(u *Unit, position, velocity rl.Vector2) rl.Vector2 {
+	acceleration := rl.Vector2{}
+	if s.Config.ApplyGravity {
+		acceleration = s.Config.Gravity
+
+		if s.Config.ApplyHeat && s.Config.ApplyBuoyancy {
+			acceleration = rl.Vector2Add(acceleration, s.buoyancyAcceleration(u))
+		}
+	}
+
+	acceleration = rl.Vector2Add(acceleration, s.fieldAcceleration(position))
+	return rl.Vector2Add(acceleration, s.interactionAcceleration(u, position, velocity))
+}
+
+// PreviousPosition is rebuilt from the integrated velocity so collision
+// responses keep working on it.
+func (s *Simulation) integrate(u *Unit, integrator Integrator, dt float32) {
+	if dt <= 0 {
+		return
+	}
+
+	if _, ok := integrator.(PositionVerlet); ok {
+		u.accelerate(s.localAcceleration(u, u.Position, u.Velocity(dt)))
+		u.updatePositionWithVerlet(dt)
+		return
+	}
+
+	pairwise := u.Acceleration
+	acceleration := func(position, velocity rl.Vector2) rl.Vector2 {
+		return rl.Vector2Add(pairwise, s.localAcceleration(u, position, velocity))
+	}
+
+	position, velocity := integrator.Step(u.Position, u.Velocity(dt), acceleration, dt)
+
+	u.Position = position
+	u.PreviousPosition = rl.Vector2Subtract(position, rl.Vector2Scale(velocity, dt))
+	u.Acceleration = rl.Vector2{}
+	u.Orientation += u.AngularVelocity * dt
+}
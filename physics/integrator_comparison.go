@@ -0,0 +1,159 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-fluid-simulator/config"
+	"github.com/alexanderi96/go-fluid-simulator/metrics"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type IntegratorDrift struct {
+	Integrator string
+	Drift      float32
+}
+
+func (s *Simulation) TotalEnergy() float32 {
+	energy := s.KineticEnergy() + s.ExternalPotentialEnergy()
+	if s.Config.ApplySelfGravity {
+		energy += s.GravitationalPotentialEnergy()
+	}
+	if s.Config.InteractionMode == config.InteractionLennardJones {
+		energy += s.LennardJonesPotentialEnergy()
+	}
+	return energy
+}
+
+// CompareIntegrators runs copies of the scene in the background, leaving the
+// simulation itself untouched.
+func (s *Simulation) CompareIntegrators(duration, dt float32) <-chan []IntegratorDrift {
+	results := make(chan []IntegratorDrift, 1)
+	if dt <= 0 {
+		results <- nil
+		return results
+	}
+
+	clones := make([]*Simulation, 0, len(config.Integrators))
+	for _, integrator := range config.Integrators {
+		clone := s.clone(dt)
+		clone.Config.Integrator = integrator
+		clones = append(clones, clone)
+	}
+
+	steps := int(duration / dt)
+	go func() {
+		drifts := make([]IntegratorDrift, 0, len(clones))
+		for _, clone := range clones {
+			reference := clone.TotalEnergy()
+			for i := 0; i < steps; i++ {
+				clone.UpdateWithVerletIntegration()
+			}
+
+			drift := float32(0)
+			if reference != 0 {
+				drift = (clone.TotalEnergy() - reference) / float32(math.Abs(float64(reference)))
+			}
+			drifts = append(drifts, IntegratorDrift{Integrator: clone.Config.Integrator, Drift: drift})
+		}
+		results <- drifts
+	}()
+
+	return results
+}
+
+// The stored displacements are rescaled to dt so velocities keep their value
+// in m/s.
+func (s *Simulation) clone(dt float32) *Simulation {
+	cfg := *s.Config
+
+	scale := float32(1)
+	if s.Metrics.Timestep > 0 {
+		scale = dt / s.Metrics.Timestep
+	}
+
+	units := make(map[*Unit]*Unit, len(s.Fluid))
+	fluid := make([]*Unit, 0, len(s.Fluid))
+	for _, unit := range s.Fluid {
+		copied := *unit
+		copied.PreviousPosition = rl.Vector2Subtract(unit.Position, rl.Vector2Scale(unit.GetVelocityWithVerlet(), scale))
+		copied.Asleep = false
+		copied.island = nil
+		units[unit] = &copied
+		fluid = append(fluid, &copied)
+	}
+	remap := func(original []*Unit) []*Unit {
+		copied := make([]*Unit, 0, len(original))
+		for _, unit := range original {
+			copied = append(copied, units[unit])
+		}
+		return copied
+	}
+
+	obstacles := make([]*Obstacle, 0, len(s.Obstacles))
+	for _, obstacle := range s.Obstacles {
+		copied := *obstacle
+		copied.points = append(obstacle.points[:0:0], obstacle.points...)
+		obstacles = append(obstacles, &copied)
+	}
+
+	constraints := make([]*Constraint, 0, len(s.Constraints))
+	for _, constraint := range s.Constraints {
+		copied := *constraint
+		copied.A, copied.B = units[constraint.A], units[constraint.B]
+		constraints = append(constraints, &copied)
+	}
+
+	softBodies := make([]*SoftBody, 0, len(s.SoftBodies))
+	for _, body := range s.SoftBodies {
+		copied := *body
+		copied.Units = remap(body.Units)
+		softBodies = append(softBodies, &copied)
+	}
+
+	rigidBodies := make([]*RigidBody, 0, len(s.RigidBodies))
+	for _, body := range s.RigidBodies {
+		copied := *body
+		copied.Units = remap(body.Units)
+		copied.offsets = append(copied.offsets[:0:0], body.offsets...)
+		for _, unit := range copied.Units {
+			unit.Body = &copied
+		}
+		rigidBodies = append(rigidBodies, &copied)
+	}
+
+	fields := make([]ForceField, 0, len(s.Fields))
+	for _, field := range s.Fields {
+		fields = append(fields, copyField(field))
+	}
+
+	return &Simulation{
+		Fluid:       fluid,
+		Obstacles:   obstacles,
+		Fields:      fields,
+		Constraints: constraints,
+		SoftBodies:  softBodies,
+		RigidBodies: rigidBodies,
+		Metrics:     &metrics.Metrics{Timestep: dt, Substeps: 1},
+		Config:      &cfg,
+		Time:        s.Time,
+	}
+}
+
+// The GUI may move the original while the copy is in use.
+func copyField(field ForceField) ForceField {
+	switch field := field.(type) {
+	case *PointField:
+		copied := *field
+		return &copied
+	case *Vortex:
+		copied := *field
+		return &copied
+	case *Wind:
+		copied := *field
+		return &copied
+	case *Turbulence:
+		copied := *field
+		return &copied
+	}
+	return field
+}
@@ -0,0 +1,69 @@
+package physics
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type InteractionKind int32
+
+const (
+	InteractionNone InteractionKind = iota
+	InteractionGrab
+	InteractionPush
+	InteractionPull
+	InteractionStir
+)
+
+// The GUI sets the Interaction every frame and the engine applies it during the
+// step, so it also works while stepping frame by frame.
+type Interaction struct {
+	Kind     InteractionKind
+	Position rl.Vector2
+	Velocity rl.Vector2
+	Radius   float32
+	Unit     *Unit
+}
+
+// The grabbed unit hangs from the cursor on a critically damped spring, and the
+// other tools fade out linearly at Radius.
+func (s *Simulation) interactionAcceleration(u *Unit, position, velocity rl.Vector2) rl.Vector2 {
+	interaction := s.Interaction
+
+	if interaction.Kind == InteractionGrab {
+		if u != interaction.Unit {
+			return rl.Vector2{}
+		}
+
+		stiffness := s.Config.GrabStiffness
+		damping := 2 * float32(math.Sqrt(float64(stiffness)))
+		spring := rl.Vector2Scale(rl.Vector2Subtract(interaction.Position, position), stiffness)
+		return rl.Vector2Subtract(spring, rl.Vector2Scale(velocity, damping))
+	}
+
+	fromCursor := rl.Vector2Subtract(position, interaction.Position)
+	distance := rl.Vector2Length(fromCursor)
+	if interaction.Radius <= 0 || distance >= interaction.Radius {
+		return rl.Vector2{}
+	}
+	weight := 1 - distance/interaction.Radius
+
+	switch interaction.Kind {
+	case InteractionPush, InteractionPull:
+		if distance == 0 {
+			return rl.Vector2{}
+		}
+		strength := s.Config.PushStrength * weight
+		if interaction.Kind == InteractionPull {
+			strength = -strength
+		}
+		return rl.Vector2Scale(fromCursor, strength/distance)
+
+	case InteractionStir:
+		slip := rl.Vector2Subtract(interaction.Velocity, velocity)
+		return rl.Vector2Scale(slip, s.Config.StirStrength*weight)
+	}
+
+	return rl.Vector2{}
+}
@@ -0,0 +1,81 @@
+package physics
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Closer pairs get the force at lennardJonesMinDistance·σ, so a single bad step
+// cannot fling them apart.
+const lennardJonesMinDistance = 0.8
+
+func (s *Simulation) lennardJonesReach() float32 {
+	return s.Config.LJCutoff * s.Config.LJSigma
+}
+
+// 24ε/r·(2(σ/r)¹² − (σ/r)⁶), positive when repelling.
+func (s *Simulation) lennardJonesForce(distance float32) float32 {
+	sigma := s.Config.LJSigma
+	if sigma <= 0 || distance >= s.lennardJonesReach() {
+		return 0
+	}
+
+	distance = float32(math.Max(float64(distance), float64(lennardJonesMinDistance*sigma)))
+	ratio6 := float32(math.Pow(float64(sigma/distance), 6))
+	return 24 * s.Config.LJEpsilon / distance * (2*ratio6*ratio6 - ratio6)
+}
+
+// Each pair is visited from both sides, so every visit only accelerates its own
+// unit.
+func (s *Simulation) applyLennardJones(grid *neighbourGrid) {
+	for _, unit := range s.Fluid {
+		if unit == nil {
+			continue
+		}
+
+		grid.forEachNear(unit.Position, func(other *Unit) {
+			if other == unit || (unit.Body != nil && unit.Body == other.Body) {
+				return
+			}
+
+			delta := s.separation(unit.Position, other.Position)
+			distance := rl.Vector2Length(delta)
+			if distance == 0 {
+				return
+			}
+
+			force := s.lennardJonesForce(distance)
+			unit.accelerate(rl.Vector2Scale(delta, -force/distance/unit.Mass))
+		})
+	}
+}
+
+func (s *Simulation) LennardJonesPotentialEnergy() float32 {
+	sigma := s.Config.LJSigma
+	if sigma <= 0 {
+		return 0
+	}
+
+	grid := s.newNeighbourGrid(s.lennardJonesReach())
+
+	energy := float32(0)
+	for _, unit := range s.Fluid {
+		grid.forEachNear(unit.Position, func(other *Unit) {
+			if other == unit {
+				return
+			}
+
+			distance := rl.Vector2Length(s.separation(unit.Position, other.Position))
+			if distance == 0 || distance >= s.lennardJonesReach() {
+				return
+			}
+
+			ratio6 := float32(math.Pow(float64(sigma/distance), 6))
+			energy += 4 * s.Config.LJEpsilon * (ratio6*ratio6 - ratio6)
+		})
+	}
+
+	// Every pair was counted from both ends.
+	return energy / 2
+}
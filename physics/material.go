@@ -0,0 +1,72 @@
+package physics
+
+import (
+	"github.com/alexanderi96/go-fluid-simulator/config"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func (u *Unit) MaterialName() string {
+	if u.Material == nil {
+		return ""
+	}
+	return u.Material.Name
+}
+
+func frictionOf(u *Unit, cfg *config.Config) float32 {
+	if u.Material == nil {
+		return cfg.UnitFriction
+	}
+	return u.Material.Friction
+}
+
+func cohesionOf(u *Unit, cfg *config.Config) float32 {
+	if u.Material == nil {
+		return cfg.CohesionStrength
+	}
+	return u.Material.Cohesion
+}
+
+func pairFriction(a, b *Unit, cfg *config.Config) float32 {
+	return (frictionOf(a, cfg) + frictionOf(b, cfg)) / 2
+}
+
+func pairCohesion(a, b *Unit, cfg *config.Config) float32 {
+	if a.Material != nil && b.Material != nil {
+		if interaction := cfg.Interaction(a.Material.Name, b.Material.Name); interaction != nil && interaction.Cohesion != nil {
+			return *interaction.Cohesion
+		}
+	}
+	return (cohesionOf(a, cfg) + cohesionOf(b, cfg)) / 2
+}
+
+// Units without a material keep the plain positional response.
+func pairRestitution(a, b *Unit, cfg *config.Config) (float32, bool) {
+	if a.Material == nil && b.Material == nil {
+		return 0, false
+	}
+
+	if a.Material != nil && b.Material != nil {
+		if interaction := cfg.Interaction(a.Material.Name, b.Material.Name); interaction != nil && interaction.Restitution != nil {
+			return *interaction.Restitution, true
+		}
+	}
+	return (a.Elasticity + b.Elasticity) / 2, true
+}
+
+func restitutionImpulse(approach, massA, massB, restitution float32) float32 {
+	return -(1 + restitution) * approach / (1/massA + 1/massB)
+}
+
+// applyRestitution replaces the velocity the overlap correction implied with
+// an impulse along normal, which points from a to b.
+func applyRestitution(a, b *Unit, normal, velocityA, velocityB rl.Vector2, restitution float32) {
+	approach := rl.Vector2DotProduct(rl.Vector2Subtract(velocityB, velocityA), normal)
+	if approach < 0 {
+		impulse := restitutionImpulse(approach, a.Mass, b.Mass, restitution)
+		velocityA = rl.Vector2Subtract(velocityA, rl.Vector2Scale(normal, impulse/a.Mass))
+		velocityB = rl.Vector2Add(velocityB, rl.Vector2Scale(normal, impulse/b.Mass))
+	}
+
+	a.PreviousPosition = rl.Vector2Subtract(a.Position, velocityA)
+	b.PreviousPosition = rl.Vector2Subtract(b.Position, velocityB)
+}
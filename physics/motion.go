@@ -0,0 +1,115 @@
+package physics
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/alexanderi96/go-fluid-simulator/config"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type pose struct {
+	pivot  rl.Vector2
+	offset rl.Vector2
+	angle  float32
+}
+
+func rotate(v rl.Vector2, angle float32) rl.Vector2 {
+	sin, cos := math.Sincos(float64(angle))
+	return rl.Vector2{
+		X: v.X*float32(cos) - v.Y*float32(sin),
+		Y: v.X*float32(sin) + v.Y*float32(cos),
+	}
+}
+
+func (p pose) apply(point rl.Vector2) rl.Vector2 {
+	local := rotate(rl.Vector2Subtract(point, p.pivot), p.angle)
+	return rl.Vector2Add(rl.Vector2Add(p.pivot, p.offset), local)
+}
+
+func (p pose) invert(point rl.Vector2) rl.Vector2 {
+	local := rl.Vector2Subtract(point, rl.Vector2Add(p.pivot, p.offset))
+	return rl.Vector2Add(p.pivot, rotate(local, -p.angle))
+}
+
+func motionPose(m *config.Motion, t float32) pose {
+	p := pose{pivot: m.Pivot}
+
+	switch m.Type {
+	case config.MotionSinusoid:
+		wave := float32(math.Sin(2*math.Pi*float64(m.Frequency*t) + float64(m.Phase)))
+		p.offset = rl.Vector2Scale(m.Amplitude, wave)
+		p.angle = m.AngularAmplitude * wave
+
+	case config.MotionRotation:
+		p.angle = m.AngularVelocity * t
+
+	case config.MotionKeyframes:
+		p.offset, p.angle = interpolateKeyframes(m.Keyframes, t, m.Loop)
+	}
+
+	return p
+}
+
+func interpolateKeyframes(keyframes []config.Keyframe, t float32, loop bool) (rl.Vector2, float32) {
+	if len(keyframes) == 0 {
+		return rl.Vector2{}, 0
+	}
+
+	last := keyframes[len(keyframes)-1]
+	if loop && last.Time > 0 {
+		t = float32(math.Mod(float64(t), float64(last.Time)))
+	}
+
+	if t <= keyframes[0].Time {
+		return keyframes[0].Offset, keyframes[0].Angle
+	}
+
+	for i := 1; i < len(keyframes); i++ {
+		from, to := keyframes[i-1], keyframes[i]
+		if t > to.Time {
+			continue
+		}
+
+		k := float32(0)
+		if to.Time > from.Time {
+			k = (t - from.Time) / (to.Time - from.Time)
+		}
+		return rl.Vector2Lerp(from.Offset, to.Offset, k), from.Angle + (to.Angle-from.Angle)*k
+	}
+
+	return last.Offset, last.Angle
+}
+
+func newBoundaryObstacle(b config.Boundary) (*Obstacle, error) {
+	var obstacle *Obstacle
+
+	switch b.Shape {
+	case "segment":
+		if len(b.Points) < 2 {
+			return nil, fmt.Errorf("segment boundary needs 2 points, got %d", len(b.Points))
+		}
+		obstacle = NewSegmentObstacle(b.Points[0], b.Points[1])
+	case "box":
+		if len(b.Points) < 2 {
+			return nil, fmt.Errorf("box boundary needs 2 points, got %d", len(b.Points))
+		}
+		obstacle = NewBoxObstacle(b.Points[0], b.Points[1], b.Thickness)
+	case "circle":
+		obstacle = NewCircleObstacle(b.Center, b.Radius)
+	case "polygon":
+		if len(b.Points) < 3 {
+			return nil, fmt.Errorf("polygon boundary needs at least 3 points, got %d", len(b.Points))
+		}
+		obstacle = NewPolygonObstacle(b.Points)
+	default:
+		return nil, fmt.Errorf("unknown boundary shape %q", b.Shape)
+	}
+
+	motion := b.Motion
+	obstacle.Restitution = b.Restitution
+	obstacle.Motion = &motion
+	obstacle.fromConfig = true
+
+	return obstacle, nil
+}
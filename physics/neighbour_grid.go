@@ -0,0 +1,137 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-fluid-simulator/config"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type neighbourGrid struct {
+	config   *config.Config
+	cellSize float32
+	columns  int
+	rows     int
+	cells    map[[2]int][]*Unit
+}
+
+func (s *Simulation) newNeighbourGrid(reach float32) *neighbourGrid {
+	grid := &neighbourGrid{
+		config:   s.Config,
+		cellSize: float32(math.Max(float64(reach), 1e-3)),
+		cells:    map[[2]int][]*Unit{},
+	}
+
+	if s.Config.ContainerShape != config.ContainerCircle {
+		if s.Config.PeriodicX {
+			grid.columns = int(math.Max(1, math.Floor(float64(s.Config.GameX/grid.cellSize))))
+		}
+		if s.Config.PeriodicY {
+			grid.rows = int(math.Max(1, math.Floor(float64(s.Config.GameY/grid.cellSize))))
+		}
+	}
+
+	for _, unit := range s.Fluid {
+		if unit == nil {
+			continue
+		}
+		cell := grid.cellOf(unit.Position)
+		grid.cells[cell] = append(grid.cells[cell], unit)
+	}
+
+	return grid
+}
+
+// On a periodic axis the cells are stretched to fill the size exactly.
+func cellIndex(value, cellSize, size float32, count int) int {
+	if count == 0 {
+		return int(math.Floor(float64(value / cellSize)))
+	}
+	index := int(math.Floor(float64(value / size * float32(count))))
+	return ((index % count) + count) % count
+}
+
+func (g *neighbourGrid) cellOf(position rl.Vector2) [2]int {
+	return [2]int{
+		cellIndex(position.X, g.cellSize, g.config.GameX, g.columns),
+		cellIndex(position.Y, g.cellSize, g.config.GameY, g.rows),
+	}
+}
+
+// neighbourIndices lists each cell once, even when a periodic axis has fewer
+// than three.
+func neighbourIndices(index, count int) []int {
+	if count == 0 {
+		return []int{index - 1, index, index + 1}
+	}
+
+	indices := make([]int, 0, 3)
+	for offset := -1; offset <= 1 && len(indices) < count; offset++ {
+		indices = append(indices, ((index+offset)%count+count)%count)
+	}
+	return indices
+}
+
+func (g *neighbourGrid) forEachNear(position rl.Vector2, fn func(*Unit)) {
+	cell := g.cellOf(position)
+
+	for _, column := range neighbourIndices(cell[0], g.columns) {
+		for _, row := range neighbourIndices(cell[1], g.rows) {
+			for _, unit := range g.cells[[2]int{column, row}] {
+				fn(unit)
+			}
+		}
+	}
+}
+
+func (g *neighbourGrid) forEachAlong(start, end rl.Vector2, margin float32, fn func(*Unit)) {
+	low := rl.Vector2{
+		X: float32(math.Min(float64(start.X), float64(end.X))) - margin,
+		Y: float32(math.Min(float64(start.Y), float64(end.Y))) - margin,
+	}
+	high := rl.Vector2{
+		X: float32(math.Max(float64(start.X), float64(end.X))) + margin,
+		Y: float32(math.Max(float64(start.Y), float64(end.Y))) + margin,
+	}
+
+	for _, column := range cellRange(low.X, high.X, g.cellSize, g.config.GameX, g.columns) {
+		for _, row := range cellRange(low.Y, high.Y, g.cellSize, g.config.GameY, g.rows) {
+			for _, unit := range g.cells[[2]int{column, row}] {
+				fn(unit)
+			}
+		}
+	}
+}
+
+func cellRange(low, high, cellSize, size float32, count int) []int {
+	scale := 1 / cellSize
+	if count > 0 {
+		scale = float32(count) / size
+	}
+
+	first := int(math.Floor(float64(low * scale)))
+	last := int(math.Floor(float64(high * scale)))
+	if count > 0 && last-first >= count {
+		first, last = 0, count-1
+	}
+
+	indices := make([]int, 0, last-first+1)
+	for index := first; index <= last; index++ {
+		if count > 0 {
+			indices = append(indices, ((index%count)+count)%count)
+		} else {
+			indices = append(indices, index)
+		}
+	}
+	return indices
+}
+
+func (s *Simulation) maxUnitRadius() float32 {
+	radius := float32(0)
+	for _, unit := range s.Fluid {
+		if unit != nil && unit.Radius > radius {
+			radius = unit.Radius
+		}
+	}
+	return radius
+}
@@ -0,0 +1,58 @@
+package physics
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type neighbourGrid3D struct {
+	cellSize float32
+	cells    map[[3]int][]int
+}
+
+func newNeighbourGrid3D(units []*Unit3D, reach float32) *neighbourGrid3D {
+	grid := &neighbourGrid3D{
+		cellSize: float32(math.Max(float64(reach), 1e-3)),
+		cells:    map[[3]int][]int{},
+	}
+
+	for i, unit := range units {
+		cell := grid.cellOf(unit.Position)
+		grid.cells[cell] = append(grid.cells[cell], i)
+	}
+
+	return grid
+}
+
+func (g *neighbourGrid3D) cellOf(position rl.Vector3) [3]int {
+	return [3]int{
+		int(math.Floor(float64(position.X / g.cellSize))),
+		int(math.Floor(float64(position.Y / g.cellSize))),
+		int(math.Floor(float64(position.Z / g.cellSize))),
+	}
+}
+
+func (g *neighbourGrid3D) forEachNear(position rl.Vector3, fn func(int)) {
+	cell := g.cellOf(position)
+
+	for x := cell[0] - 1; x <= cell[0]+1; x++ {
+		for y := cell[1] - 1; y <= cell[1]+1; y++ {
+			for z := cell[2] - 1; z <= cell[2]+1; z++ {
+				for _, index := range g.cells[[3]int{x, y, z}] {
+					fn(index)
+				}
+			}
+		}
+	}
+}
+
+func (s *Simulation) maxUnitRadius3D() float32 {
+	radius := float32(0)
+	for _, unit := range s.Fluid3D {
+		if unit.Radius > radius {
+			radius = unit.Radius
+		}
+	}
+	return radius
+}
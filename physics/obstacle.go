@@ -0,0 +1,268 @@
+package physics
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/alexanderi96/go-fluid-simulator/config"
+	"github.com/alexanderi96/go-fluid-simulator/utils"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type ObstacleShape int
+
+const (
+	ObstacleSegment ObstacleShape = iota
+	ObstacleCircle
+	ObstaclePolygon
+)
+
+var obstacleShapeNames = map[ObstacleShape]string{
+	ObstacleSegment: "segment",
+	ObstacleCircle:  "circle",
+	ObstaclePolygon: "polygon",
+}
+
+func (o ObstacleShape) MarshalText() ([]byte, error) {
+	name, ok := obstacleShapeNames[o]
+	if !ok {
+		return nil, fmt.Errorf("unknown obstacle shape %d", o)
+	}
+	return []byte(name), nil
+}
+
+func (o *ObstacleShape) UnmarshalText(text []byte) error {
+	for shape, name := range obstacleShapeNames {
+		if name == string(text) {
+			*o = shape
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown obstacle shape %q", text)
+}
+
+// Points and Center are where an obstacle with a Motion is at rest.
+type Obstacle struct {
+	Shape       ObstacleShape  `toml:"shape"`
+	Points      []rl.Vector2   `toml:"points,omitempty"`
+	Center      rl.Vector2     `toml:"center,omitempty"`
+	Radius      float32        `toml:"radius,omitempty"`
+	Restitution *float32       `toml:"restitution,omitempty"`
+	Motion      *config.Motion `toml:"motion,omitempty"`
+
+	points       []rl.Vector2
+	center       rl.Vector2
+	pose         pose
+	previousPose pose
+	fromConfig   bool
+}
+
+func NewSegmentObstacle(start, end rl.Vector2) *Obstacle {
+	return &Obstacle{Shape: ObstacleSegment, Points: []rl.Vector2{start, end}}
+}
+
+func NewCircleObstacle(center rl.Vector2, radius float32) *Obstacle {
+	return &Obstacle{Shape: ObstacleCircle, Center: center, Radius: radius}
+}
+
+func NewPolygonObstacle(points []rl.Vector2) *Obstacle {
+	return &Obstacle{Shape: ObstaclePolygon, Points: points}
+}
+
+func NewBoxObstacle(start, end rl.Vector2, thickness float32) *Obstacle {
+	direction := rl.Vector2Subtract(end, start)
+	length := rl.Vector2Length(direction)
+	if length == 0 {
+		direction = rl.Vector2{X: 1, Y: 0}
+	} else {
+		direction = rl.Vector2Scale(direction, 1/length)
+	}
+
+	offset := rl.Vector2Scale(rl.Vector2{X: -direction.Y, Y: direction.X}, thickness/2)
+
+	return NewPolygonObstacle([]rl.Vector2{
+		rl.Vector2Add(start, offset),
+		rl.Vector2Add(end, offset),
+		rl.Vector2Subtract(end, offset),
+		rl.Vector2Subtract(start, offset),
+	})
+}
+
+func (s *Simulation) AddObstacle(obstacle *Obstacle) {
+	s.Obstacles = append(s.Obstacles, obstacle)
+	s.WakeAll()
+}
+
+func (s *Simulation) ClearObstacles() {
+	obstacles := []*Obstacle{}
+	for _, obstacle := range s.Obstacles {
+		if obstacle.fromConfig {
+			obstacles = append(obstacles, obstacle)
+		} else {
+			s.wakeOnObstacle(obstacle)
+		}
+	}
+	s.Obstacles = obstacles
+}
+
+func (o *Obstacle) update(t float32) {
+	if o.Motion == nil {
+		return
+	}
+
+	o.previousPose = o.pose
+	o.pose = motionPose(o.Motion, t)
+
+	if o.points == nil {
+		o.previousPose = o.pose
+		o.points = make([]rl.Vector2, len(o.Points))
+	}
+
+	for i, point := range o.Points {
+		o.points[i] = o.pose.apply(point)
+	}
+	o.center = o.pose.apply(o.Center)
+}
+
+func (o *Obstacle) WorldPoints() []rl.Vector2 {
+	if o.points == nil {
+		return o.Points
+	}
+	return o.points
+}
+
+func (o *Obstacle) WorldCenter() rl.Vector2 {
+	if o.points == nil {
+		return o.Center
+	}
+	return o.center
+}
+
+func (o *Obstacle) displacementAt(point rl.Vector2) rl.Vector2 {
+	if o.Motion == nil {
+		return rl.Vector2{}
+	}
+
+	rest := o.pose.invert(point)
+	return rl.Vector2Subtract(point, o.previousPose.apply(rest))
+}
+
+func (o *Obstacle) restitution(wallElasticity float32) float32 {
+	if o.Restitution != nil {
+		return *o.Restitution
+	}
+	return wallElasticity
+}
+
+func closestPointOnSegment(p, a, b rl.Vector2) rl.Vector2 {
+	ab := rl.Vector2Subtract(b, a)
+	lengthSquared := rl.Vector2LenSqr(ab)
+	if lengthSquared == 0 {
+		return a
+	}
+
+	t := rl.Clamp(rl.Vector2DotProduct(rl.Vector2Subtract(p, a), ab)/lengthSquared, 0, 1)
+	return rl.Vector2Add(a, rl.Vector2Scale(ab, t))
+}
+
+// contact returns the outward normal and penetration depth of a circle at
+// position.
+func (o *Obstacle) contact(position rl.Vector2, radius float32) (rl.Vector2, float32, bool) {
+	points := o.WorldPoints()
+
+	switch o.Shape {
+	case ObstacleCircle:
+		return circleContact(position, o.WorldCenter(), radius+o.Radius)
+
+	case ObstacleSegment:
+		if len(points) < 2 {
+			return rl.Vector2{}, 0, false
+		}
+		closest := closestPointOnSegment(position, points[0], points[1])
+		if closest == position {
+			normal, ok := edgeNormal(points[0], points[1])
+			return normal, radius, ok
+		}
+		return circleContact(position, closest, radius)
+
+	case ObstaclePolygon:
+		if len(points) < 3 {
+			return rl.Vector2{}, 0, false
+		}
+
+		closest := points[0]
+		closestEdge := 0
+		closestDistanceSquared := float32(math.MaxFloat32)
+		for i := range points {
+			candidate := closestPointOnSegment(position, points[i], points[(i+1)%len(points)])
+			distanceSquared := rl.Vector2LenSqr(rl.Vector2Subtract(position, candidate))
+			if distanceSquared < closestDistanceSquared {
+				closest = candidate
+				closestEdge = i
+				closestDistanceSquared = distanceSquared
+			}
+		}
+
+		if closestDistanceSquared == 0 {
+			normal, ok := edgeNormal(points[closestEdge], points[(closestEdge+1)%len(points)])
+			if signedArea(points) < 0 {
+				normal = rl.Vector2Negate(normal)
+			}
+			return normal, radius, ok
+		}
+
+		if utils.PointInPolygon(position, points) {
+			distance := float32(math.Sqrt(float64(closestDistanceSquared)))
+			normal := rl.Vector2Scale(rl.Vector2Subtract(closest, position), 1/distance)
+			return normal, radius + distance, true
+		}
+
+		return circleContact(position, closest, radius)
+	}
+
+	return rl.Vector2{}, 0, false
+}
+
+// edgeNormal points out of polygons with a positive signed area.
+func edgeNormal(a, b rl.Vector2) (rl.Vector2, bool) {
+	edge := rl.Vector2Subtract(b, a)
+	length := rl.Vector2Length(edge)
+	if length == 0 {
+		return rl.Vector2{}, false
+	}
+	return rl.Vector2{X: edge.Y / length, Y: -edge.X / length}, true
+}
+
+func circleContact(position, closest rl.Vector2, reach float32) (rl.Vector2, float32, bool) {
+	delta := rl.Vector2Subtract(position, closest)
+	distanceSquared := rl.Vector2LenSqr(delta)
+
+	if distanceSquared >= reach*reach || distanceSquared == 0 {
+		return rl.Vector2{}, 0, false
+	}
+
+	distance := float32(math.Sqrt(float64(distanceSquared)))
+	return rl.Vector2Scale(delta, 1/distance), reach - distance, true
+}
+
+func (u *Unit) checkObstacleCollisionVerlet(obstacle *Obstacle, cfg *config.Config, dt float32) {
+	normal, depth, ok := obstacle.contact(u.Position, u.Radius)
+	if !ok {
+		return
+	}
+
+	contactPoint := rl.Vector2Subtract(u.Position, rl.Vector2Scale(normal, u.Radius-depth))
+	surfaceVelocity := obstacle.displacementAt(contactPoint)
+	relativeVelocity := rl.Vector2Subtract(u.GetVelocityWithVerlet(), surfaceVelocity)
+
+	u.Position = rl.Vector2Add(u.Position, rl.Vector2Scale(normal, depth))
+
+	normalSpeed := rl.Vector2DotProduct(relativeVelocity, normal)
+	if normalSpeed < 0 {
+		relativeVelocity = rl.Vector2Subtract(relativeVelocity, rl.Vector2Scale(normal, (1+obstacle.restitution(cfg.WallElasticity))*normalSpeed))
+	}
+
+	u.PreviousPosition = rl.Vector2Subtract(u.Position, rl.Vector2Add(relativeVelocity, surfaceVelocity))
+
+	u.applyWallFriction(normal, surfaceVelocity, depth, cfg.WallFriction, dt)
+}
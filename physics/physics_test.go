@@ -1 +1,1136 @@
 package physics
+
+import (
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexanderi96/go-fluid-simulator/config"
+	"github.com/alexanderi96/go-fluid-simulator/metrics"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/google/uuid"
+)
+
+const testTimestep = float32(1.0 / 60)
+
+// newTestSimulation returns an empty simulation in a 10×10 m box, without
+// gravity, stepping testTimestep seconds at a time.
+func newTestSimulation() *Simulation {
+	return &Simulation{
+		Metrics: &metrics.Metrics{Timestep: testTimestep, Substeps: 1},
+		Config: &config.Config{
+			GameX:            10,
+			GameY:            10,
+			ParticleRadius:   0.1,
+			ParticleMass:     1,
+			WallElasticity:   1,
+			SolverIterations: 1,
+			OverRelaxation:   1,
+			CCDThreshold:     0.5,
+		},
+	}
+}
+
+// addTestUnit adds a unit of radius 0.1 m and mass 1 kg at position moving
+// with velocity in m/s.
+func addTestUnit(s *Simulation, position, velocity rl.Vector2) *Unit {
+	unit := &Unit{
+		Position:         position,
+		PreviousPosition: rl.Vector2Subtract(position, rl.Vector2Scale(velocity, s.Metrics.Timestep)),
+		Radius:           0.1,
+		Mass:             1,
+		Elasticity:       1,
+	}
+	s.Fluid = append(s.Fluid, unit)
+	return unit
+}
+
+func near(a, b, tolerance float32) bool {
+	return math.Abs(float64(a-b)) <= float64(tolerance)
+}
+
+func TestSelfGravityMatchesDirectSumAtThetaZero(t *testing.T) {
+	s := newTestSimulation()
+	s.Config.GravitationalConstant = 1
+	s.Config.GravitySoftening = 0.01
+
+	positions := []rl.Vector2{{X: 1, Y: 1}, {X: 2, Y: 1.5}, {X: 4, Y: 3}, {X: 1.2, Y: 5}, {X: 6, Y: 6}, {X: 5.5, Y: 2}}
+	for i, position := range positions {
+		unit := addTestUnit(s, position, rl.Vector2{})
+		unit.Mass = float32(i + 1)
+	}
+
+	exact := make([]rl.Vector2, len(s.Fluid))
+	softening := s.Config.GravitySoftening * s.Config.GravitySoftening
+	for i, unit := range s.Fluid {
+		for _, other := range s.Fluid {
+			if other == unit {
+				continue
+			}
+			delta := rl.Vector2Subtract(other.Position, unit.Position)
+			distanceSquared := rl.Vector2LenSqr(delta) + softening
+			scale := other.Mass / (distanceSquared * float32(math.Sqrt(float64(distanceSquared))))
+			exact[i] = rl.Vector2Add(exact[i], rl.Vector2Scale(delta, scale))
+		}
+	}
+
+	tests := []struct {
+		theta     float32
+		tolerance float32
+	}{
+		{theta: 0, tolerance: 1e-4},
+		{theta: 0.5, tolerance: 0.05},
+	}
+
+	for _, test := range tests {
+		for _, unit := range s.Fluid {
+			unit.Acceleration = rl.Vector2{}
+		}
+		s.Config.BarnesHutTheta = test.theta
+		s.applySelfGravity()
+
+		for i, unit := range s.Fluid {
+			relative := rl.Vector2Length(rl.Vector2Subtract(unit.Acceleration, exact[i])) / rl.Vector2Length(exact[i])
+			if relative > test.tolerance {
+				t.Errorf("theta %.1f, unit %d: acceleration %v, direct sum %v", test.theta, i, unit.Acceleration, exact[i])
+			}
+		}
+	}
+}
+
+func TestCloneKeepsVelocities(t *testing.T) {
+	s := newTestSimulation()
+	unit := addTestUnit(s, rl.Vector2{X: 5, Y: 5}, rl.Vector2{X: 2, Y: -1})
+
+	for _, dt := range []float32{testTimestep, testTimestep / 4, testTimestep * 2} {
+		clone := s.clone(dt)
+		velocity := clone.Fluid[0].Velocity(clone.Metrics.Timestep)
+		if !near(velocity.X, 2, 1e-3) || !near(velocity.Y, -1, 1e-3) {
+			t.Errorf("dt %.4f: cloned velocity %v, want %v", dt, velocity, unit.Velocity(s.Metrics.Timestep))
+		}
+	}
+}
+
+func TestIntegratorDriftInFreeFall(t *testing.T) {
+	s := newTestSimulation()
+	s.Config.ApplyGravity = true
+	s.Config.Gravity = rl.Vector2{Y: 9.81}
+	addTestUnit(s, rl.Vector2{X: 5, Y: 1}, rl.Vector2{X: 1})
+
+	tolerances := map[string]float32{
+		config.IntegratorVerlet:         0.06,
+		config.IntegratorVelocityVerlet: 1e-3,
+		config.IntegratorRK4:            1e-3,
+	}
+
+	drifts := <-s.CompareIntegrators(0.5, testTimestep)
+	if len(drifts) != len(config.Integrators) {
+		t.Fatalf("got %d results, want one per integrator", len(drifts))
+	}
+
+	for _, result := range drifts {
+		if math.Abs(float64(result.Drift)) > float64(tolerances[result.Integrator]) {
+			t.Errorf("%s: drift %.4f, want within %.4f", result.Integrator, result.Drift, tolerances[result.Integrator])
+		}
+	}
+
+	if s.Fluid[0].Position != (rl.Vector2{X: 5, Y: 1}) {
+		t.Errorf("the comparison moved the simulation's own unit to %v", s.Fluid[0].Position)
+	}
+}
+
+func TestSweepStopsFastUnits(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(s *Simulation)
+		start     rl.Vector2
+		end       rl.Vector2
+		position  rl.Vector2
+		velocity  rl.Vector2
+		hitUnitAt rl.Vector2
+	}{
+		{
+			name:     "wall",
+			start:    rl.Vector2{X: 9.5, Y: 5},
+			end:      rl.Vector2{X: 11.5, Y: 5},
+			position: rl.Vector2{X: 8.3, Y: 5},
+			velocity: rl.Vector2{X: -2},
+		},
+		{
+			name: "obstacle",
+			setup: func(s *Simulation) {
+				s.Obstacles = append(s.Obstacles, NewSegmentObstacle(rl.Vector2{X: 5, Y: 4}, rl.Vector2{X: 5, Y: 6}))
+			},
+			start:    rl.Vector2{X: 4, Y: 5},
+			end:      rl.Vector2{X: 6, Y: 5},
+			position: rl.Vector2{X: 3.8, Y: 5},
+			velocity: rl.Vector2{X: -2},
+		},
+		{
+			name: "unit",
+			setup: func(s *Simulation) {
+				addTestUnit(s, rl.Vector2{X: 5, Y: 5}, rl.Vector2{})
+			},
+			start:     rl.Vector2{X: 3, Y: 5},
+			end:       rl.Vector2{X: 7, Y: 5},
+			position:  rl.Vector2{X: 4.8, Y: 5},
+			velocity:  rl.Vector2{},
+			hitUnitAt: rl.Vector2{X: 4, Y: 0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newTestSimulation()
+			unit := &Unit{Position: test.end, PreviousPosition: test.start, Radius: 0.1, Mass: 1, Elasticity: 1}
+			s.Fluid = append(s.Fluid, unit)
+			if test.setup != nil {
+				test.setup(s)
+			}
+
+			starts := make([]rl.Vector2, len(s.Fluid))
+			for i, other := range s.Fluid {
+				starts[i] = other.Position
+			}
+			starts[0] = test.start
+			s.sweepUnits(starts)
+
+			if !near(unit.Position.X, test.position.X, 1e-4) || !near(unit.Position.Y, test.position.Y, 1e-4) {
+				t.Errorf("position %v, want %v", unit.Position, test.position)
+			}
+			velocity := unit.GetVelocityWithVerlet()
+			if !near(velocity.X, test.velocity.X, 1e-4) || !near(velocity.Y, test.velocity.Y, 1e-4) {
+				t.Errorf("velocity %v, want %v", velocity, test.velocity)
+			}
+			if len(s.Fluid) > 1 {
+				hit := s.Fluid[1].GetVelocityWithVerlet()
+				if !near(hit.X, test.hitUnitAt.X, 1e-4) || !near(hit.Y, test.hitUnitAt.Y, 1e-4) {
+					t.Errorf("hit unit velocity %v, want %v", hit, test.hitUnitAt)
+				}
+			}
+		})
+	}
+}
+
+func TestJacobiRestitutionAddsUpContacts(t *testing.T) {
+	s := newTestSimulation()
+	s.Config.SolverOrdering = config.SolverJacobi
+	material := &config.Material{Name: "test", Elasticity: 1}
+
+	hitter := addTestUnit(s, rl.Vector2{X: 5, Y: 5}, rl.Vector2{X: 1})
+	upper := addTestUnit(s, rl.Vector2{X: 5.18, Y: 5.05}, rl.Vector2{})
+	lower := addTestUnit(s, rl.Vector2{X: 5.18, Y: 4.95}, rl.Vector2{})
+	for _, unit := range s.Fluid {
+		unit.Material = material
+	}
+
+	s.relaxJacobi([]solverPair{{a: hitter, b: upper}, {a: hitter, b: lower}}, 1, true)
+
+	velocity := hitter.Velocity(testTimestep)
+	if !near(velocity.Y, 0, 1e-3) {
+		t.Errorf("hitter deflected to %v by one contact only", velocity)
+	}
+	if velocity.X >= 1 {
+		t.Errorf("hitter kept its speed: %v", velocity)
+	}
+
+	upperVelocity := upper.Velocity(testTimestep)
+	lowerVelocity := lower.Velocity(testTimestep)
+	if upperVelocity.X <= 0 || lowerVelocity.X <= 0 {
+		t.Errorf("both hit units should move on, got %v and %v", upperVelocity, lowerVelocity)
+	}
+	if !near(upperVelocity.X, lowerVelocity.X, 1e-3) || !near(upperVelocity.Y, -lowerVelocity.Y, 1e-3) {
+		t.Errorf("symmetric contacts gave %v and %v", upperVelocity, lowerVelocity)
+	}
+}
+
+func TestSolverOrderingsSeparateOverlappingPair(t *testing.T) {
+	for _, ordering := range []string{config.SolverGaussSeidel, config.SolverJacobi} {
+		t.Run(ordering, func(t *testing.T) {
+			s := newTestSimulation()
+			s.Config.SolverOrdering = ordering
+			s.Config.SolverIterations = 8
+			a := addTestUnit(s, rl.Vector2{X: 4.95, Y: 5}, rl.Vector2{})
+			b := addTestUnit(s, rl.Vector2{X: 5.05, Y: 5}, rl.Vector2{})
+
+			s.solveOverlaps(s.newNeighbourGrid(2 * s.maxUnitRadius()))
+
+			if distance := rl.Vector2Distance(a.Position, b.Position); !near(distance, a.Radius+b.Radius, 1e-4) {
+				t.Errorf("distance %v after solving, want %v", distance, a.Radius+b.Radius)
+			}
+			if !near(a.Position.X+b.Position.X, 10, 1e-4) || a.Position.Y != 5 || b.Position.Y != 5 {
+				t.Errorf("pair moved off its centre: %v, %v", a.Position, b.Position)
+			}
+			if s.Metrics.MaxResidualOverlap > 1e-4 {
+				t.Errorf("residual overlap %v", s.Metrics.MaxResidualOverlap)
+			}
+		})
+	}
+}
+
+func TestIslandSleepsAndWakes(t *testing.T) {
+	// The pair is just out of overlap, within the touching slop, and two cells
+	// apart on a grid as coarse as an overlap.
+	asleep := func(t *testing.T) (*Simulation, *Unit, *Unit) {
+		s := newTestSimulation()
+		s.Config.SleepSpeed = 0.01
+		s.Config.SleepAcceleration = 0.1
+		s.Config.SleepDelay = 0.05
+		a := addTestUnit(s, rl.Vector2{X: 4.799, Y: 9.9}, rl.Vector2{})
+		b := addTestUnit(s, rl.Vector2{X: 5.004, Y: 9.9}, rl.Vector2{})
+
+		for i := 0; i < 5; i++ {
+			s.updateSleep(testTimestep)
+		}
+		if !a.Asleep || !b.Asleep || a.island == nil || a.island != b.island {
+			t.Fatalf("resting pair should fall asleep as one island")
+		}
+		return s, a, b
+	}
+
+	tests := []struct {
+		name    string
+		disturb func(s *Simulation)
+	}{
+		{
+			name: "awake unit",
+			disturb: func(s *Simulation) {
+				addTestUnit(s, rl.Vector2{X: 5.2, Y: 9.9}, rl.Vector2{X: -1})
+				s.wakeDisturbed(s.newNeighbourGrid(2 * s.maxUnitRadius()))
+			},
+		},
+		{
+			name: "force field",
+			disturb: func(s *Simulation) {
+				s.Fields = append(s.Fields, NewWind(rl.Rectangle{X: 4.5, Y: 9.5, Width: 1, Height: 0.5}, rl.Vector2{X: 1}))
+				s.wakeDisturbed(s.newNeighbourGrid(2 * s.maxUnitRadius()))
+			},
+		},
+		{
+			name: "removed unit",
+			disturb: func(s *Simulation) {
+				s.Config.OpenFloor = true
+				addTestUnit(s, rl.Vector2{X: 5.004, Y: 10.105}, rl.Vector2{})
+				s.removeEscapedUnits()
+				if len(s.Fluid) != 2 {
+					t.Fatalf("the unit below the floor should be removed")
+				}
+			},
+		},
+		{
+			name: "removed obstacle",
+			disturb: func(s *Simulation) {
+				s.Obstacles = append(s.Obstacles, NewSegmentObstacle(rl.Vector2{X: 5.105, Y: 9.5}, rl.Vector2{X: 5.105, Y: 10}))
+				s.ClearObstacles()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, a, b := asleep(t)
+			test.disturb(s)
+			if a.Asleep || b.Asleep {
+				t.Errorf("island should wake, asleep: %v, %v", a.Asleep, b.Asleep)
+			}
+		})
+	}
+
+	t.Run("far disturbance", func(t *testing.T) {
+		s, a, b := asleep(t)
+		addTestUnit(s, rl.Vector2{X: 2, Y: 2}, rl.Vector2{X: -1})
+		s.Fields = append(s.Fields, NewWind(rl.Rectangle{X: 1, Y: 1, Width: 2, Height: 2}, rl.Vector2{X: 1}))
+		s.wakeDisturbed(s.newNeighbourGrid(2 * s.maxUnitRadius()))
+		if !a.Asleep || !b.Asleep {
+			t.Errorf("island woken by a unit and a field far away")
+		}
+	})
+}
+
+func TestChooseTimestepFollowsCourantCondition(t *testing.T) {
+	frame := testTimestep
+	tests := []struct {
+		name        string
+		adaptive    bool
+		speed       float32
+		maxSubsteps int
+		dt          float32
+		substeps    int
+	}{
+		{name: "fixed", speed: 10, maxSubsteps: 20, dt: frame, substeps: 1},
+		{name: "at rest", adaptive: true, maxSubsteps: 20, dt: frame / 2, substeps: 2},
+		{name: "courant", adaptive: true, speed: 10, maxSubsteps: 20, dt: frame / 4, substeps: 4},
+		{name: "min timestep", adaptive: true, speed: 100, maxSubsteps: 20, dt: frame / 17, substeps: 17},
+		{name: "max substeps", adaptive: true, speed: 100, maxSubsteps: 5, dt: 0.001, substeps: 5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newTestSimulation()
+			s.Config.AdaptiveTimestep = test.adaptive
+			s.Config.CourantNumber = 0.5
+			s.Config.MinTimestep = 0.001
+			s.Config.MaxTimestep = 0.01
+			s.Config.MaxSubsteps = test.maxSubsteps
+			addTestUnit(s, rl.Vector2{X: 5, Y: 5}, rl.Vector2{X: test.speed})
+
+			dt, substeps := s.chooseTimestep(frame)
+			if !near(dt, test.dt, 1e-6) || substeps != test.substeps {
+				t.Errorf("got %v x %d, want %v x %d", dt, substeps, test.dt, test.substeps)
+			}
+		})
+	}
+}
+
+func TestUnknownIntegratorIsRejected(t *testing.T) {
+	if integrator, err := NewIntegrator(config.IntegratorEuler); err != nil || integrator != (PositionVerlet{}) {
+		t.Errorf("euler should run position Verlet, got %v, %v", integrator, err)
+	}
+
+	s := newTestSimulation()
+	s.Config.Integrator = "leapfrog"
+	addTestUnit(s, rl.Vector2{X: 5, Y: 5}, rl.Vector2{X: 1})
+	if err := s.UpdateWithVerletIntegration(); err == nil {
+		t.Errorf("unknown integrator accepted")
+	}
+}
+
+func TestHeatedIslandWakes(t *testing.T) {
+	s := newTestSimulation()
+	s.Config.ApplySleep = true
+	s.Config.SleepSpeed = 0.01
+	s.Config.SleepAcceleration = 0.1
+	s.Config.SleepDelay = 0.05
+	s.Config.ApplyGravity = true
+	s.Config.Gravity = rl.Vector2{Y: 9.81}
+	s.restingGravity = s.Config.Gravity
+	s.Config.ApplyHeat = true
+	s.Config.ApplyBuoyancy = true
+	s.Config.SpecificHeat = 1
+	s.Config.Conductivity = 100
+	s.Config.WallConductivity = 10
+	s.Config.AmbientTemperature = 300
+	s.Config.FloorTemperature = 400
+	s.Config.ThermalExpansion = 0.01
+
+	bottom := addTestUnit(s, rl.Vector2{X: 5, Y: 9.9}, rl.Vector2{})
+	top := addTestUnit(s, rl.Vector2{X: 5, Y: 9.71}, rl.Vector2{})
+	bottom.Temperature = 300
+	top.Temperature = 350
+	for i := 0; i < 5; i++ {
+		s.updateSleep(testTimestep)
+	}
+	if !bottom.Asleep || !top.Asleep {
+		t.Fatalf("stack should fall asleep")
+	}
+
+	s.UpdateWithVerletIntegration()
+	if !bottom.Asleep || !top.Asleep {
+		t.Fatalf("stack woke before heating")
+	}
+	if bottom.Temperature <= 300 || top.Temperature >= 350 {
+		t.Errorf("no heat flow in the sleeping stack: %v, %v", bottom.Temperature, top.Temperature)
+	}
+
+	s.UpdateWithVerletIntegration()
+	if bottom.Asleep || top.Asleep {
+		t.Errorf("heated stack should wake")
+	}
+}
+
+func TestConstraintAcrossPeriodicEdge(t *testing.T) {
+	s := newTestSimulation()
+	s.Config.PeriodicX = true
+	s.Config.SpringStiffness = 10
+	a := addTestUnit(s, rl.Vector2{X: 0.1, Y: 5}, rl.Vector2{})
+	b := addTestUnit(s, rl.Vector2{X: 9.9, Y: 5}, rl.Vector2{})
+
+	c := s.NewConstraint(a, b)
+	if !near(c.RestLength, 0.2, 1e-4) {
+		t.Fatalf("rest length %v across the edge, want 0.2", c.RestLength)
+	}
+
+	a.Position.X = 0.2
+	if strain := s.Strain(c); !near(strain, 0.5, 1e-3) {
+		t.Errorf("strain %v, want 0.5", strain)
+	}
+
+	s.applySpringForces(testTimestep)
+	if a.Acceleration.X >= 0 || b.Acceleration.X <= 0 {
+		t.Errorf("spring should pull the ends together across the edge, got %v and %v", a.Acceleration, b.Acceleration)
+	}
+
+	c.Rigid = true
+	s.solveRigidConstraints()
+	if distance := rl.Vector2Length(s.separation(a.Position, b.Position)); !near(distance, 0.2, 1e-4) {
+		t.Errorf("rigid link at %v, want 0.2", distance)
+	}
+}
+
+func TestSoftBodyAcrossPeriodicEdge(t *testing.T) {
+	s := newTestSimulation()
+	s.Config.PeriodicX = true
+	s.Config.SoftBodyParticles = 8
+	s.Config.SoftBodyPressure = 10
+	body := s.NewSoftBody(rl.Vector2{X: 5, Y: 5}, 1)
+
+	for _, unit := range body.Units {
+		unit.Position.X -= 5
+		if unit.Position.X < 0 {
+			unit.Position.X += s.Config.GameX
+		}
+	}
+
+	if area := s.SoftBodyArea(body); !near(area, body.RestArea, 1e-3) {
+		t.Errorf("area %v across the edge, want %v", area, body.RestArea)
+	}
+
+	s.applySoftBodyPressure()
+	for _, unit := range body.Units {
+		if !near(rl.Vector2Length(unit.Acceleration), 0, 1e-3) {
+			t.Errorf("pressure at rest area pushes a unit by %v", unit.Acceleration)
+		}
+	}
+}
+
+func TestSpawnShapesWithoutSpacing(t *testing.T) {
+	cfg := &config.Config{}
+	if positions := LineSpawnPositions(rl.Vector2{}, rl.Vector2{X: 1}, cfg); len(positions) != 0 {
+		t.Errorf("line without spacing gave %d positions", len(positions))
+	}
+	if positions := RectangleSpawnPositions(rl.Rectangle{Width: 1, Height: 1}, cfg); len(positions) != 0 {
+		t.Errorf("rectangle without spacing gave %d positions", len(positions))
+	}
+}
+
+func TestPolygonContactNormals(t *testing.T) {
+	square := []rl.Vector2{{X: 4, Y: 4}, {X: 6, Y: 4}, {X: 6, Y: 6}, {X: 4, Y: 6}}
+	reversed := []rl.Vector2{{X: 4, Y: 6}, {X: 6, Y: 6}, {X: 6, Y: 4}, {X: 4, Y: 4}}
+
+	tests := []struct {
+		name     string
+		obstacle *Obstacle
+		position rl.Vector2
+		normal   rl.Vector2
+		depth    float32
+	}{
+		{name: "outside", obstacle: NewPolygonObstacle(square), position: rl.Vector2{X: 5, Y: 3.95}, normal: rl.Vector2{Y: -1}, depth: 0.05},
+		{name: "inside", obstacle: NewPolygonObstacle(square), position: rl.Vector2{X: 5.9, Y: 5}, normal: rl.Vector2{X: 1}, depth: 0.2},
+		{name: "on edge", obstacle: NewPolygonObstacle(square), position: rl.Vector2{X: 5, Y: 4}, normal: rl.Vector2{Y: -1}, depth: 0.1},
+		{name: "on edge reversed", obstacle: NewPolygonObstacle(reversed), position: rl.Vector2{X: 5, Y: 6}, normal: rl.Vector2{Y: 1}, depth: 0.1},
+		{name: "on vertex", obstacle: NewPolygonObstacle(square), position: rl.Vector2{X: 4, Y: 4}, normal: rl.Vector2{Y: -1}, depth: 0.1},
+		{name: "on segment", obstacle: NewSegmentObstacle(rl.Vector2{X: 4, Y: 5}, rl.Vector2{X: 6, Y: 5}), position: rl.Vector2{X: 5, Y: 5}, normal: rl.Vector2{Y: -1}, depth: 0.1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			normal, depth, ok := test.obstacle.contact(test.position, 0.1)
+			if !ok {
+				t.Fatalf("no contact")
+			}
+			if !near(normal.X, test.normal.X, 1e-4) || !near(normal.Y, test.normal.Y, 1e-4) || !near(depth, test.depth, 1e-4) {
+				t.Errorf("normal %v depth %v, want %v depth %v", normal, depth, test.normal, test.depth)
+			}
+		})
+	}
+}
+
+func TestSceneRoundTripWithLowerCaseKeys(t *testing.T) {
+	s := newTestSimulation()
+	s.Obstacles = append(s.Obstacles, NewSegmentObstacle(rl.Vector2{X: 1, Y: 2}, rl.Vector2{X: 3, Y: 4}))
+	s.Fields = append(s.Fields, NewWind(rl.Rectangle{X: 1, Y: 1, Width: 2, Height: 3}, rl.Vector2{X: 0.5}))
+
+	path := t.TempDir() + "/scene.toml"
+	if err := s.SaveScene(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "X") || strings.Contains(string(data), "Width") {
+		t.Errorf("scene written with upper case keys:\n%s", data)
+	}
+
+	loaded := newTestSimulation()
+	if err := loaded.LoadScene(path); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Obstacles) != 1 || loaded.Obstacles[0].Points[1] != (rl.Vector2{X: 3, Y: 4}) {
+		t.Errorf("obstacles loaded as %v", loaded.Obstacles)
+	}
+	if len(loaded.Fields) != 1 || *loaded.Fields[0].(*Wind) != *s.Fields[0].(*Wind) {
+		t.Errorf("fields loaded as %v", loaded.Fields)
+	}
+}
+
+func TestChooseTimestepIn3D(t *testing.T) {
+	s := newTestSimulation()
+	s.Config.Mode3D = true
+	s.Config.AdaptiveTimestep = true
+	s.Config.CourantNumber = 0.5
+	s.Config.MinTimestep = 0.001
+	s.Config.MaxTimestep = 0.01
+	s.Config.MaxSubsteps = 20
+	position := rl.Vector3{X: 5, Y: 5, Z: 5}
+	s.Fluid3D = append(s.Fluid3D, &Unit3D{
+		Position:         position,
+		PreviousPosition: rl.Vector3Subtract(position, rl.Vector3{X: 10 * testTimestep}),
+		Radius:           0.1,
+		Mass:             1,
+	})
+
+	if dt, substeps := s.chooseTimestep(testTimestep); !near(dt, testTimestep/4, 1e-6) || substeps != 4 {
+		t.Errorf("got %v x %d, want %v x 4", dt, substeps, testTimestep/4)
+	}
+}
+
+func TestSpawnedUnitsJoinOnUpdate(t *testing.T) {
+	s := newTestSimulation()
+	s.Config.ParticleNumber = 2
+	s.Config.InitialSpeedMax = 10
+	s.NewFluidWithVelocity(rl.Vector2{X: 2, Y: 5})
+
+	deadline := time.Now().Add(time.Second)
+	for len(s.Fluid) < 2 && time.Now().Before(deadline) {
+		s.addSpawnedUnits()
+		time.Sleep(time.Millisecond)
+	}
+
+	if len(s.Fluid) != 2 {
+		t.Fatalf("%d units spawned, want 2", len(s.Fluid))
+	}
+	for _, unit := range s.Fluid {
+		if velocity := unit.Velocity(testTimestep); !near(velocity.X, 6, 1e-3) || !near(velocity.Y, 0, 1e-3) {
+			t.Errorf("spawned at %v m/s, want 6 m/s to the right", velocity)
+		}
+	}
+}
+
+func TestSpawnShapesCountAndSpacing(t *testing.T) {
+	cfg := &config.Config{ParticleRadius: 0.125}
+	spacing := spawnSpacing(cfg)
+	triangle := []rl.Vector2{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 0, Y: 2}}
+
+	tests := []struct {
+		name      string
+		positions []rl.Vector2
+		count     int
+		inside    func(rl.Vector2) bool
+	}{
+		{
+			name:      "rectangle",
+			positions: RectangleSpawnPositions(rl.Rectangle{X: 1, Y: 1, Width: 1, Height: 1}, cfg),
+			count:     25,
+			inside: func(p rl.Vector2) bool {
+				return p.X >= 1 && p.X <= 2 && p.Y >= 1 && p.Y <= 2
+			},
+		},
+		{
+			name:      "circle",
+			positions: CircleSpawnPositions(rl.Vector2{X: 5, Y: 5}, 1, cfg),
+			inside: func(p rl.Vector2) bool {
+				return rl.Vector2Distance(p, rl.Vector2{X: 5, Y: 5}) <= 1
+			},
+		},
+		{
+			name:      "line",
+			positions: LineSpawnPositions(rl.Vector2{}, rl.Vector2{X: 1}, cfg),
+			count:     5,
+			inside: func(p rl.Vector2) bool {
+				return p.Y == 0 && p.X >= 0 && p.X <= 1
+			},
+		},
+		{
+			name:      "polygon",
+			positions: PolygonSpawnPositions(triangle, cfg),
+			inside: func(p rl.Vector2) bool {
+				return p.X >= 0 && p.Y >= 0 && p.X+p.Y <= 2
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if len(test.positions) == 0 || test.count > 0 && len(test.positions) != test.count {
+				t.Fatalf("%d positions, want %d", len(test.positions), test.count)
+			}
+			for i, p := range test.positions {
+				if !test.inside(p) {
+					t.Errorf("position %v outside the shape", p)
+				}
+				for _, q := range test.positions[i+1:] {
+					if rl.Vector2Distance(p, q) < spacing-1e-4 {
+						t.Errorf("positions %v and %v closer than %v", p, q, spacing)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMovingBoundaryFollowsItsMotion(t *testing.T) {
+	s := newTestSimulation()
+	floor := NewSegmentObstacle(rl.Vector2{X: 4, Y: 5}, rl.Vector2{X: 6, Y: 5})
+	floor.Motion = &config.Motion{
+		Type: config.MotionKeyframes,
+		Keyframes: []config.Keyframe{
+			{Time: 0},
+			{Time: 1, Offset: rl.Vector2{Y: -1}},
+		},
+	}
+	s.Obstacles = append(s.Obstacles, floor)
+	unit := addTestUnit(s, rl.Vector2{X: 5, Y: 4.9}, rl.Vector2{})
+
+	s.Metrics.Timestep = 0.05
+	for i := 0; i < 2; i++ {
+		s.UpdateWithVerletIntegration()
+	}
+
+	if points := floor.WorldPoints(); !near(points[0].Y, 4.9, 1e-4) || !near(points[1].Y, 4.9, 1e-4) {
+		t.Errorf("floor at %v, want y 4.9", points)
+	}
+	if unit.Position.Y > 4.8+1e-4 {
+		t.Errorf("unit at %v not lifted by the rising floor", unit.Position)
+	}
+	if velocity := unit.GetVelocityWithVerlet(); velocity.Y >= 0 {
+		t.Errorf("unit not carried upwards, moved by %v", velocity)
+	}
+
+	s.Reset()
+	if s.Time != 0 {
+		t.Errorf("time %v after reset", s.Time)
+	}
+}
+
+func TestRotatingBoundary(t *testing.T) {
+	paddle := NewSegmentObstacle(rl.Vector2{X: 5, Y: 5}, rl.Vector2{X: 6, Y: 5})
+	paddle.Motion = &config.Motion{Type: config.MotionRotation, Pivot: rl.Vector2{X: 5, Y: 5}, AngularVelocity: math.Pi / 2}
+
+	paddle.update(0)
+	paddle.update(1)
+
+	if end := paddle.WorldPoints()[1]; !near(end.X, 5, 1e-4) || !near(end.Y, 6, 1e-4) {
+		t.Errorf("paddle end at %v after a quarter turn, want (5, 6)", end)
+	}
+	if moved := paddle.displacementAt(rl.Vector2{X: 5, Y: 6}); !near(moved.X, -1, 1e-4) || !near(moved.Y, 1, 1e-4) {
+		t.Errorf("paddle end moved by %v, want (-1, 1)", moved)
+	}
+}
+
+func TestPeriodicSeparationAndWrap(t *testing.T) {
+	s := newTestSimulation()
+	s.Config.PeriodicX = true
+
+	if delta := s.separation(rl.Vector2{X: 9.9, Y: 5}, rl.Vector2{X: 0.1, Y: 5.5}); !near(delta.X, 0.2, 1e-4) || !near(delta.Y, 0.5, 1e-4) {
+		t.Errorf("separation across the edge %v, want (0.2, 0.5)", delta)
+	}
+	if delta := s.separation(rl.Vector2{X: 5, Y: 9.9}, rl.Vector2{X: 5, Y: 0.1}); !near(delta.Y, -9.8, 1e-4) {
+		t.Errorf("separation along the closed axis %v, want -9.8", delta.Y)
+	}
+
+	unit := addTestUnit(s, rl.Vector2{X: 10.05, Y: 5}, rl.Vector2{X: 3})
+	s.applyContainer(unit)
+	if !near(unit.Position.X, 0.05, 1e-4) {
+		t.Errorf("unit wrapped to %v, want x 0.05", unit.Position)
+	}
+	if velocity := unit.Velocity(testTimestep); !near(velocity.X, 3, 1e-3) {
+		t.Errorf("wrapping changed the velocity to %v", velocity)
+	}
+}
+
+func TestCircleContainerBouncesUnits(t *testing.T) {
+	s := newTestSimulation()
+	s.Config.ContainerShape = config.ContainerCircle
+	unit := addTestUnit(s, rl.Vector2{X: 9.95, Y: 5}, rl.Vector2{X: 3})
+
+	s.applyContainer(unit)
+
+	if !near(unit.Position.X, 9.9, 1e-4) {
+		t.Errorf("unit at %v, want pushed back to x 9.9", unit.Position)
+	}
+	if velocity := unit.Velocity(testTimestep); !near(velocity.X, -3, 1e-3) {
+		t.Errorf("velocity %v after the bounce, want -3", velocity)
+	}
+}
+
+func TestKineticEnergyAndExportInSIUnits(t *testing.T) {
+	s := newTestSimulation()
+	unit := addTestUnit(s, rl.Vector2{X: 1, Y: 2}, rl.Vector2{X: 3, Y: 4})
+	unit.Mass = 2
+
+	if energy := s.KineticEnergy(); !near(energy, 25, 1e-3) {
+		t.Errorf("kinetic energy %v J, want 25 J", energy)
+	}
+
+	path := t.TempDir() + "/units.csv"
+	if err := s.ExportCSV(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("%d lines exported, want a header and one unit", len(lines))
+	}
+	fields := strings.Split(lines[1], ",")
+	if fields[1] != "1" || fields[2] != "2" || fields[6] != "2" {
+		t.Errorf("exported %q, want the position in metres and the mass in kilograms", lines[1])
+	}
+	if vx, err := strconv.ParseFloat(fields[3], 32); err != nil || !near(float32(vx), 3, 1e-3) {
+		t.Errorf("exported vx %q, want 3 m/s", fields[3])
+	}
+}
+
+func TestUnits3DCollideAndBounceOffWalls(t *testing.T) {
+	s := newTestSimulation()
+	s.Config.GameZ = 10
+	velocity := float32(2 * testTimestep)
+	a := &Unit3D{Position: rl.Vector3{X: 4.95, Y: 5, Z: 5}, Radius: 0.1, Mass: 1, Elasticity: 1}
+	b := &Unit3D{Position: rl.Vector3{X: 5.05, Y: 5, Z: 5}, Radius: 0.1, Mass: 1, Elasticity: 1}
+	a.PreviousPosition = rl.Vector3Subtract(a.Position, rl.Vector3{X: velocity})
+	b.PreviousPosition = rl.Vector3Add(b.Position, rl.Vector3{X: velocity})
+
+	calculateCollisionWithVerlet3D(a, b)
+
+	if distance := rl.Vector3Distance(a.Position, b.Position); !near(distance, 0.2, 1e-5) {
+		t.Errorf("units %v apart after the collision, want 0.2", distance)
+	}
+	if va, vb := a.GetVelocityWithVerlet().X, b.GetVelocityWithVerlet().X; !near(va, -velocity, 1e-6) || !near(vb, velocity, 1e-6) {
+		t.Errorf("velocities %v and %v, want them swapped", va, vb)
+	}
+
+	wall := &Unit3D{Position: rl.Vector3{X: 5, Y: 5, Z: 9.95}, Radius: 0.1, Mass: 1}
+	wall.PreviousPosition = rl.Vector3Subtract(wall.Position, rl.Vector3{Z: velocity})
+	wall.checkWallCollisionVerlet(s.Config)
+
+	if !near(wall.Position.Z, 9.9, 1e-5) || wall.GetVelocityWithVerlet().Z >= 0 {
+		t.Errorf("unit at %v moving %v after the wall", wall.Position, wall.GetVelocityWithVerlet())
+	}
+}
+
+func TestDampedSpringSettlesAtRestLength(t *testing.T) {
+	s := newTestSimulation()
+	s.Config.SpringStiffness = 50
+	s.Config.SpringDamping = 2
+	a := addTestUnit(s, rl.Vector2{X: 4, Y: 5}, rl.Vector2{})
+	b := addTestUnit(s, rl.Vector2{X: 5, Y: 5}, rl.Vector2{})
+	c := s.NewConstraint(a, b)
+
+	b.Position.X = 5.5
+	b.PreviousPosition.X = 5.5
+
+	for i := 0; i < 600; i++ {
+		if err := s.UpdateWithVerletIntegration(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if distance := rl.Vector2Distance(a.Position, b.Position); !near(distance, c.RestLength, 1e-3) {
+		t.Errorf("spring settled at %v, want its rest length %v", distance, c.RestLength)
+	}
+}
+
+func TestConstraintBreaksBeyondItsStrain(t *testing.T) {
+	s := newTestSimulation()
+	s.Config.ConstraintBreakStrain = 0.5
+	a := addTestUnit(s, rl.Vector2{X: 4, Y: 5}, rl.Vector2{})
+	b := addTestUnit(s, rl.Vector2{X: 5, Y: 5}, rl.Vector2{})
+	s.NewConstraint(a, b)
+
+	b.Position.X = 5.4
+	s.breakConstraints()
+	if len(s.Constraints) != 1 {
+		t.Fatalf("constraint broke at a strain of 0.4")
+	}
+
+	b.Position.X = 5.6
+	s.breakConstraints()
+	if len(s.Constraints) != 0 {
+		t.Errorf("constraint held at a strain of 0.6")
+	}
+}
+
+func TestRigidBodyKeepsItsShapeAndSpins(t *testing.T) {
+	s := newTestSimulation()
+	a := addTestUnit(s, rl.Vector2{X: 4, Y: 5}, rl.Vector2{})
+	b := addTestUnit(s, rl.Vector2{X: 6, Y: 5}, rl.Vector2{})
+	body := s.NewRigidBody([]*Unit{a, b})
+
+	// A knock on one end moves the centre and turns the body.
+	b.Position.Y += 0.1
+	s.matchRigidBodies(testTimestep)
+
+	if distance := rl.Vector2Distance(a.Position, b.Position); !near(distance, 2, 1e-4) {
+		t.Errorf("members %v apart, want 2", distance)
+	}
+	if !near(body.CenterOfMass.Y, 5.05, 1e-4) {
+		t.Errorf("centre of mass at %v, want y 5.05", body.CenterOfMass)
+	}
+	if body.AngularVelocity <= 0 || a.Position.Y >= body.CenterOfMass.Y {
+		t.Errorf("body should turn clockwise on screen, angular velocity %v", body.AngularVelocity)
+	}
+	if velocity := a.Velocity(testTimestep); velocity.Y >= b.Velocity(testTimestep).Y {
+		t.Errorf("members move at %v and %v, want the knocked end faster", velocity, b.Velocity(testTimestep))
+	}
+}
+
+func TestBlockOnSlopeHoldsBelowFrictionAngle(t *testing.T) {
+	angle := float64(20 * math.Pi / 180)
+	direction := rl.Vector2{X: float32(math.Cos(angle)), Y: float32(math.Sin(angle))}
+	up := rl.Vector2{X: direction.Y, Y: -direction.X}
+	start := rl.Vector2{X: 1, Y: 3}
+
+	slide := func(friction float32) float32 {
+		s := newTestSimulation()
+		s.Config.ApplyGravity = true
+		s.Config.Gravity = rl.Vector2{Y: 9.81}
+		s.Config.WallFriction = friction
+		s.Config.WallElasticity = 0
+		s.AddObstacle(NewSegmentObstacle(start, rl.Vector2Add(start, rl.Vector2Scale(direction, 8))))
+
+		units := []*Unit{}
+		for i := 0; i < 3; i++ {
+			along := rl.Vector2Add(start, rl.Vector2Scale(direction, 4+0.2*float32(i)))
+			units = append(units, addTestUnit(s, rl.Vector2Add(along, rl.Vector2Scale(up, 0.1)), rl.Vector2{}))
+		}
+		block := s.NewRigidBody(units)
+		origin := block.CenterOfMass
+
+		for i := 0; i < 120; i++ {
+			if err := s.UpdateWithVerletIntegration(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rl.Vector2DotProduct(rl.Vector2Subtract(block.CenterOfMass, origin), direction)
+	}
+
+	if distance := slide(0.8); math.Abs(float64(distance)) > 0.01 {
+		t.Errorf("block slid %v m with a friction of 0.8 on a 20° slope", distance)
+	}
+	if distance := slide(0.1); distance < 0.5 {
+		t.Errorf("block slid only %v m with a friction of 0.1 on a 20° slope", distance)
+	}
+}
+
+func TestDragAndVelocitySmoothing(t *testing.T) {
+	s := newTestSimulation()
+	s.Config.ApplyLinearDrag = true
+	s.Config.LinearDrag = 6
+	unit := addTestUnit(s, rl.Vector2{X: 5, Y: 5}, rl.Vector2{X: 2})
+
+	unit.applyDrag(s.Config, testTimestep)
+	if velocity := unit.Velocity(testTimestep); !near(velocity.X, 2/1.1, 1e-3) {
+		t.Errorf("linear drag left %v m/s, want %v", velocity.X, 2/1.1)
+	}
+
+	s.Config.ApplyLinearDrag = false
+	s.Config.ApplyAirDrag = true
+	s.Config.AirDrag = 1e6
+	unit.applyDrag(s.Config, testTimestep)
+	if velocity := unit.Velocity(testTimestep); velocity.X <= 0 || velocity.X > 0.01 {
+		t.Errorf("strong air drag left %v m/s, want the unit nearly stopped but not reversed", velocity.X)
+	}
+
+	s = newTestSimulation()
+	s.Config.XSPHSmoothing = 0.5
+	a := addTestUnit(s, rl.Vector2{X: 5, Y: 5}, rl.Vector2{X: 1})
+	b := addTestUnit(s, rl.Vector2{X: 5.2, Y: 5}, rl.Vector2{X: -1})
+
+	s.smoothVelocities()
+	if va, vb := a.Velocity(testTimestep), b.Velocity(testTimestep); !near(va.X, 0, 1e-3) || !near(vb.X, 0, 1e-3) {
+		t.Errorf("smoothed velocities %v and %v, want both at their mean", va, vb)
+	}
+}
+
+func TestCohesionPullsNearbyUnitsTogether(t *testing.T) {
+	s := newTestSimulation()
+	s.Config.CohesionStrength = 2
+	s.Config.CohesionRange = 0.1
+	a := addTestUnit(s, rl.Vector2{X: 5, Y: 5}, rl.Vector2{})
+	b := addTestUnit(s, rl.Vector2{X: 5.25, Y: 5}, rl.Vector2{})
+	far := addTestUnit(s, rl.Vector2{X: 8, Y: 5}, rl.Vector2{})
+	for _, unit := range s.Fluid {
+		unit.Id = uuid.New()
+	}
+
+	s.applyCohesion(s.newNeighbourGrid(0.3))
+
+	// The surfaces are 0.05 m apart, half the range, so the pull is half the
+	// strength.
+	if !near(a.Acceleration.X, 1, 1e-4) || !near(b.Acceleration.X, -1, 1e-4) {
+		t.Errorf("accelerations %v and %v, want 1 m/s² towards each other", a.Acceleration, b.Acceleration)
+	}
+	if far.Acceleration != (rl.Vector2{}) {
+		t.Errorf("unit out of range pulled at %v", far.Acceleration)
+	}
+	if bonds := s.CohesionBonds(); len(bonds) != 1 {
+		t.Errorf("%d bonds, want 1", len(bonds))
+	}
+}
+
+func TestHeatConductionConservesEnergy(t *testing.T) {
+	s := newTestSimulation()
+	s.Config.SpecificHeat = 1000
+	s.Config.Conductivity = 1e4
+	s.Config.WallConductivity = 100
+	s.Config.FloorTemperature = 400
+	s.Config.ThermalExpansion = 0.01
+	s.Config.AmbientTemperature = 300
+	s.Config.Gravity = rl.Vector2{Y: 9.81}
+	hot := addTestUnit(s, rl.Vector2{X: 5, Y: 5}, rl.Vector2{})
+	cold := addTestUnit(s, rl.Vector2{X: 5.15, Y: 5}, rl.Vector2{})
+	cold.Mass = 3
+	hot.Temperature, cold.Temperature = 400, 300
+
+	exchangeHeat(hot, cold, 0.05, s.Config, testTimestep)
+	if hot.Temperature >= 400 || cold.Temperature <= 300 {
+		t.Errorf("temperatures %v and %v, want heat to flow from hot to cold", hot.Temperature, cold.Temperature)
+	}
+	if energy := hot.Temperature + 3*cold.Temperature; !near(energy, 1300, 1e-2) {
+		t.Errorf("heat content went from 1300 to %v", energy)
+	}
+
+	for i := 0; i < 1000; i++ {
+		exchangeHeat(hot, cold, 0.05, s.Config, 1)
+	}
+	if !near(hot.Temperature, 325, 1e-2) || !near(cold.Temperature, 325, 1e-2) {
+		t.Errorf("temperatures %v and %v, want both at 325", hot.Temperature, cold.Temperature)
+	}
+
+	floor := addTestUnit(s, rl.Vector2{X: 2, Y: 9.9}, rl.Vector2{})
+	floor.Temperature = 300
+	s.exchangeWallHeat(floor, 1)
+	s.exchangeWallHeat(hot, 1)
+	if !near(floor.Temperature, 310, 1e-3) || !near(hot.Temperature, 325, 1e-2) {
+		t.Errorf("floor heated units to %v and %v, want 310 on the floor only", floor.Temperature, hot.Temperature)
+	}
+
+	if lift := s.buoyancyAcceleration(floor); !near(lift.Y, -0.981, 1e-4) {
+		t.Errorf("buoyancy %v at 310 K, want 0.981 m/s² upwards", lift)
+	}
+}
+
+func TestForceFieldFalloff(t *testing.T) {
+	center := rl.Vector2{X: 5, Y: 5}
+	region := rl.Rectangle{X: 4, Y: 4, Width: 2, Height: 2}
+
+	tests := []struct {
+		name     string
+		field    ForceField
+		position rl.Vector2
+		want     rl.Vector2
+	}{
+		{"inverse square inside the radius", NewPointField(center, 4, 1, FalloffInverseSquare), rl.Vector2{X: 5.5, Y: 5}, rl.Vector2{X: -4}},
+		{"inverse square at twice the radius", NewPointField(center, 4, 1, FalloffInverseSquare), rl.Vector2{X: 7, Y: 5}, rl.Vector2{X: -1}},
+		{"repelling", NewPointField(center, -4, 1, FalloffInverseSquare), rl.Vector2{X: 5, Y: 7}, rl.Vector2{Y: 1}},
+		{"linear halfway", NewPointField(center, 4, 2, FalloffLinear), rl.Vector2{X: 6, Y: 5}, rl.Vector2{X: -2}},
+		{"linear beyond the radius", NewPointField(center, 4, 2, FalloffLinear), rl.Vector2{X: 8, Y: 5}, rl.Vector2{}},
+		{"vortex halfway", NewVortex(center, 4, 2), rl.Vector2{X: 6, Y: 5}, rl.Vector2{Y: 2}},
+		{"vortex beyond the radius", NewVortex(center, 4, 2), rl.Vector2{X: 8, Y: 5}, rl.Vector2{}},
+		{"wind inside", NewWind(region, rl.Vector2{X: 3}), center, rl.Vector2{X: 3}},
+		{"wind outside", NewWind(region, rl.Vector2{X: 3}), rl.Vector2{X: 7, Y: 5}, rl.Vector2{}},
+		{"turbulence outside", NewTurbulence(region, 3), rl.Vector2{X: 7, Y: 5}, rl.Vector2{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.field.Acceleration(test.position, 0)
+			if !near(got.X, test.want.X, 1e-4) || !near(got.Y, test.want.Y, 1e-4) {
+				t.Errorf("acceleration %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	turbulence := NewTurbulence(region, 3)
+	for x := float32(4.1); x < 6; x += 0.1 {
+		got := turbulence.Acceleration(rl.Vector2{X: x, Y: 5.3}, 1.7)
+		if math.Abs(float64(got.X)) > 3 || math.Abs(float64(got.Y)) > 3 {
+			t.Fatalf("turbulence %v at x %v, want within its strength", got, x)
+		}
+	}
+}
+
+func TestMouseInteractions(t *testing.T) {
+	s := newTestSimulation()
+	s.Config.GrabStiffness = 100
+	s.Config.PushStrength = 10
+	s.Config.StirStrength = 2
+	grabbed := addTestUnit(s, rl.Vector2{X: 5, Y: 5}, rl.Vector2{})
+	other := addTestUnit(s, rl.Vector2{X: 5.5, Y: 5}, rl.Vector2{})
+	cursor := rl.Vector2{X: 5, Y: 4}
+
+	s.Interaction = Interaction{Kind: InteractionGrab, Position: cursor, Unit: grabbed}
+	if got := s.interactionAcceleration(grabbed, grabbed.Position, rl.Vector2{X: 1}); !near(got.X, -20, 1e-3) || !near(got.Y, -100, 1e-3) {
+		t.Errorf("grab acceleration %v, want (-20, -100)", got)
+	}
+	if got := s.interactionAcceleration(other, other.Position, rl.Vector2{}); got != (rl.Vector2{}) {
+		t.Errorf("grab moved another unit at %v", got)
+	}
+
+	s.Interaction = Interaction{Kind: InteractionPush, Position: cursor, Radius: 2}
+	if got := s.interactionAcceleration(grabbed, grabbed.Position, rl.Vector2{}); !near(got.Y, 5, 1e-4) {
+		t.Errorf("push acceleration %v, want 5 m/s² away from the cursor", got)
+	}
+	s.Interaction.Kind = InteractionPull
+	if got := s.interactionAcceleration(grabbed, grabbed.Position, rl.Vector2{}); !near(got.Y, -5, 1e-4) {
+		t.Errorf("pull acceleration %v, want 5 m/s² towards the cursor", got)
+	}
+	if got := s.interactionAcceleration(grabbed, rl.Vector2{X: 5, Y: 7}, rl.Vector2{}); got != (rl.Vector2{}) {
+		t.Errorf("pull reached %v beyond its radius", got)
+	}
+
+	s.Interaction = Interaction{Kind: InteractionStir, Position: cursor, Velocity: rl.Vector2{X: 4}, Radius: 2}
+	if got := s.interactionAcceleration(grabbed, grabbed.Position, rl.Vector2{X: 1}); !near(got.X, 3, 1e-4) {
+		t.Errorf("stir acceleration %v, want 3 m/s² along the cursor", got)
+	}
+}
+
+func TestLennardJonesForceAndThermostats(t *testing.T) {
+	s := newTestSimulation()
+	s.Config.LJEpsilon = 1
+	s.Config.LJSigma = 0.2
+	s.Config.LJCutoff = 2.5
+
+	minimum := s.Config.LJSigma * float32(math.Pow(2, 1.0/6))
+	if force := s.lennardJonesForce(minimum); !near(force, 0, 1e-3) {
+		t.Errorf("force %v at the bottom of the well, want 0", force)
+	}
+	if s.lennardJonesForce(0.9*minimum) <= 0 || s.lennardJonesForce(1.1*minimum) >= 0 {
+		t.Errorf("want repulsion inside the well's minimum and attraction outside it")
+	}
+	if force := s.lennardJonesForce(0.5); force != 0 {
+		t.Errorf("force %v beyond the cutoff", force)
+	}
+
+	addTestUnit(s, rl.Vector2{X: 5, Y: 5}, rl.Vector2{})
+	addTestUnit(s, rl.Vector2{X: 5 + minimum, Y: 5}, rl.Vector2{})
+	if energy := s.LennardJonesPotentialEnergy(); !near(energy, -1, 1e-3) {
+		t.Errorf("pair at the minimum has %v J, want -ε", energy)
+	}
+
+	for _, thermostat := range []string{config.ThermostatRescale, config.ThermostatBerendsen} {
+		t.Run(thermostat, func(t *testing.T) {
+			s := newTestSimulation()
+			s.Config.LJEpsilon = 1
+			s.Config.Thermostat = thermostat
+			s.Config.TargetTemperature = 2
+			s.Config.BerendsenTau = 0.1
+			addTestUnit(s, rl.Vector2{X: 2, Y: 5}, rl.Vector2{X: 4})
+			addTestUnit(s, rl.Vector2{X: 8, Y: 5}, rl.Vector2{Y: -2})
+
+			for i := 0; i < 120; i++ {
+				s.applyThermostat(testTimestep)
+			}
+			if temperature := s.KineticTemperature(); !near(temperature, 2, 1e-3) {
+				t.Errorf("temperature %v, want 2", temperature)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-fluid-simulator/utils"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Members move as ordinary units and are matched back onto the rigid pose
+// after every step.
+type RigidBody struct {
+	Units           []*Unit
+	Mass            float32
+	Inertia         float32
+	CenterOfMass    rl.Vector2
+	Angle           float32
+	AngularVelocity float32
+
+	offsets      []rl.Vector2
+	pointInertia float32
+}
+
+func (s *Simulation) NewRigidBody(units []*Unit) *RigidBody {
+	if len(units) == 0 {
+		return nil
+	}
+
+	body := &RigidBody{Units: units}
+
+	weighted := rl.Vector2{}
+	for _, unit := range units {
+		body.Mass += unit.Mass
+		weighted = rl.Vector2Add(weighted, rl.Vector2Scale(unit.Position, unit.Mass))
+	}
+	body.CenterOfMass = rl.Vector2Scale(weighted, 1/body.Mass)
+
+	for _, unit := range units {
+		offset := rl.Vector2Subtract(unit.Position, body.CenterOfMass)
+		body.offsets = append(body.offsets, offset)
+
+		body.pointInertia += unit.Mass * rl.Vector2LenSqr(offset)
+		body.Inertia += unit.Mass * (rl.Vector2LenSqr(offset) + unit.Radius*unit.Radius/2)
+
+		unit.Body = body
+	}
+
+	s.RigidBodies = append(s.RigidBodies, body)
+	return body
+}
+
+func (s *Simulation) NewRigidBodyInPolygon(points []rl.Vector2) *RigidBody {
+	positions := PolygonSpawnPositions(points, s.Config)
+	if len(positions) == 0 {
+		return nil
+	}
+
+	first := len(s.Fluid)
+	s.NewFluidAtPositions(positions)
+
+	units := make([]*Unit, len(positions))
+	copy(units, s.Fluid[first:])
+	return s.NewRigidBody(units)
+}
+
+func (s *Simulation) GlueUnitsIn(points []rl.Vector2) *RigidBody {
+	units := []*Unit{}
+	for _, unit := range s.Fluid {
+		if unit.Body == nil && utils.PointInPolygon(unit.Position, points) {
+			units = append(units, unit)
+		}
+	}
+
+	if len(units) < 2 {
+		return nil
+	}
+	return s.NewRigidBody(units)
+}
+
+func (b *RigidBody) match(dt float32) {
+	if dt <= 0 {
+		return
+	}
+
+	weighted := rl.Vector2{}
+	for _, unit := range b.Units {
+		weighted = rl.Vector2Add(weighted, rl.Vector2Scale(unit.Position, unit.Mass))
+	}
+	center := rl.Vector2Scale(weighted, 1/b.Mass)
+
+	// The best fitting rotation of the rest offsets onto the current ones.
+	var dot, cross float64
+	for i, unit := range b.Units {
+		rest := rotate(b.offsets[i], b.Angle)
+		current := rl.Vector2Subtract(unit.Position, center)
+		dot += float64(unit.Mass * rl.Vector2DotProduct(rest, current))
+		cross += float64(unit.Mass * rl.Vector2CrossProduct(rest, current))
+	}
+	matched := b.Angle + float32(math.Atan2(cross, dot))
+
+	// Members behave as point masses while matching, so the angular impulse
+	// they deliver is rescaled to the body's full moment of inertia.
+	predicted := b.Angle + b.AngularVelocity*dt
+	angle := predicted
+	if b.Inertia > 0 {
+		angle += (matched - predicted) * b.pointInertia / b.Inertia
+	}
+
+	b.AngularVelocity = (angle - b.Angle) / dt
+	b.Angle = angle
+
+	velocity := rl.Vector2Subtract(center, b.CenterOfMass)
+	b.CenterOfMass = center
+
+	for i, unit := range b.Units {
+		offset := rotate(b.offsets[i], b.Angle)
+		tangential := rl.Vector2Scale(rl.Vector2{X: -offset.Y, Y: offset.X}, b.AngularVelocity*dt)
+
+		unit.Position = rl.Vector2Add(center, offset)
+		unit.PreviousPosition = rl.Vector2Subtract(unit.Position, rl.Vector2Add(velocity, tangential))
+	}
+}
+
+func (s *Simulation) matchRigidBodies(dt float32) {
+	for _, body := range s.RigidBodies {
+		body.match(dt)
+	}
+}
+
+func (s *Simulation) removeRigidBodiesOf(units map[*Unit]bool) {
+	remaining := s.RigidBodies[:0]
+	for _, body := range s.RigidBodies {
+		intact := true
+		for _, unit := range body.Units {
+			if units[unit] {
+				intact = false
+				break
+			}
+		}
+
+		if intact {
+			remaining = append(remaining, body)
+		} else {
+			for _, unit := range body.Units {
+				unit.Body = nil
+			}
+		}
+	}
+	s.RigidBodies = remaining
+}
@@ -0,0 +1,114 @@
+package physics
+
+import (
+	"os"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Boundaries declared in the config file are always loaded, so they are left
+// out.
+type Scene struct {
+	Obstacles   []*Obstacle   `toml:"obstacles"`
+	PointFields []*PointField `toml:"point_fields,omitempty"`
+	Vortices    []*Vortex     `toml:"vortices,omitempty"`
+	Winds       []*Wind       `toml:"winds,omitempty"`
+	Turbulence  []*Turbulence `toml:"turbulence,omitempty"`
+}
+
+func (s *Simulation) SaveScene(path string) error {
+	obstacles := make([]*Obstacle, 0, len(s.Obstacles))
+	for _, obstacle := range s.Obstacles {
+		if !obstacle.fromConfig {
+			obstacles = append(obstacles, obstacle)
+		}
+	}
+
+	scene := Scene{
+		Obstacles: obstacles,
+	}
+	for _, field := range s.Fields {
+		switch field := field.(type) {
+		case *PointField:
+			scene.PointFields = append(scene.PointFields, field)
+		case *Vortex:
+			scene.Vortices = append(scene.Vortices, field)
+		case *Wind:
+			scene.Winds = append(scene.Winds, field)
+		case *Turbulence:
+			scene.Turbulence = append(scene.Turbulence, field)
+		}
+	}
+
+	data, err := toml.Marshal(scene)
+	if err != nil {
+		return err
+	}
+
+	// Lower case keys, like the config file.
+	generic := map[string]any{}
+	if err := toml.Unmarshal(data, &generic); err != nil {
+		return err
+	}
+	data, err = toml.Marshal(lowerKeys(generic))
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+func (s *Simulation) LoadScene(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	scene := Scene{}
+	if err := toml.Unmarshal(data, &scene); err != nil {
+		return err
+	}
+
+	obstacles := make([]*Obstacle, 0, len(s.Obstacles)+len(scene.Obstacles))
+	for _, obstacle := range s.Obstacles {
+		if obstacle.fromConfig {
+			obstacles = append(obstacles, obstacle)
+		}
+	}
+	s.Obstacles = append(obstacles, scene.Obstacles...)
+	s.WakeAll()
+
+	s.Fields = []ForceField{}
+	for _, field := range scene.PointFields {
+		s.AddField(field)
+	}
+	for _, field := range scene.Vortices {
+		s.AddField(field)
+	}
+	for _, field := range scene.Winds {
+		s.AddField(field)
+	}
+	for _, field := range scene.Turbulence {
+		s.AddField(field)
+	}
+
+	return nil
+}
+
+func lowerKeys(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		lowered := make(map[string]any, len(value))
+		for key, item := range value {
+			lowered[strings.ToLower(key)] = lowerKeys(item)
+		}
+		return lowered
+	case []any:
+		for i, item := range value {
+			value[i] = lowerKeys(item)
+		}
+		return value
+	}
+	return value
+}
@@ -0,0 +1,216 @@
+package physics
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// sleepContactSlop is a fraction of the radii sum.
+const sleepContactSlop = 0.05
+
+type island struct {
+	units []*Unit
+}
+
+func (u *Unit) wake() {
+	if u.island == nil {
+		return
+	}
+
+	for _, unit := range u.island.units {
+		unit.Asleep = false
+		unit.restTime = 0
+		unit.island = nil
+	}
+}
+
+func (s *Simulation) WakeAll() {
+	for _, unit := range s.Fluid {
+		if unit != nil {
+			unit.Asleep = false
+			unit.restTime = 0
+			unit.island = nil
+		}
+	}
+}
+
+func touching(a, b *Unit, delta rl.Vector2) bool {
+	reach := (a.Radius + b.Radius) * (1 + sleepContactSlop)
+	return rl.Vector2LenSqr(delta) < reach*reach
+}
+
+// Islands heated or cooled enough to change their buoyancy by SleepAcceleration
+// wake, and a change of gravity wakes all of them.
+func (s *Simulation) wakeDisturbed(grid *neighbourGrid) {
+	gravity := rl.Vector2{}
+	if s.Config.ApplyGravity {
+		gravity = s.Config.Gravity
+	}
+	if gravity != s.restingGravity {
+		s.restingGravity = gravity
+		s.WakeAll()
+		return
+	}
+
+	for _, unit := range s.Fluid {
+		if unit == nil {
+			continue
+		}
+
+		if !unit.Asleep {
+			grid.forEachNear(unit.Position, func(other *Unit) {
+				if other.Asleep && touching(unit, other, s.separation(unit.Position, other.Position)) {
+					other.wake()
+				}
+			})
+			continue
+		}
+
+		if s.Interaction.Kind != InteractionNone && s.interactionAcceleration(unit, unit.Position, rl.Vector2{}) != (rl.Vector2{}) {
+			unit.wake()
+			continue
+		}
+
+		if s.fieldAcceleration(unit.Position) != (rl.Vector2{}) {
+			unit.wake()
+			continue
+		}
+
+		if s.Config.ApplyHeat && s.Config.ApplyBuoyancy && gravity != (rl.Vector2{}) &&
+			rl.Vector2Distance(s.buoyancyAcceleration(unit), unit.restingBuoyancy) > s.Config.SleepAcceleration {
+			unit.wake()
+			continue
+		}
+
+		for _, obstacle := range s.Obstacles {
+			if obstacle.Motion == nil {
+				continue
+			}
+			if _, _, ok := obstacle.contact(unit.Position, unit.Radius*(1+sleepContactSlop)); ok {
+				unit.wake()
+				break
+			}
+		}
+	}
+}
+
+// The removed units may have been holding the islands up.
+func (s *Simulation) wakeResting(removed map[*Unit]bool) {
+	for _, unit := range s.Fluid {
+		if unit == nil || !unit.Asleep {
+			continue
+		}
+
+		for other := range removed {
+			if touching(unit, other, s.separation(unit.Position, other.Position)) {
+				unit.wake()
+				break
+			}
+		}
+	}
+}
+
+func (s *Simulation) wakeOnObstacle(obstacle *Obstacle) {
+	for _, unit := range s.Fluid {
+		if unit == nil || !unit.Asleep {
+			continue
+		}
+
+		if _, _, ok := obstacle.contact(unit.Position, unit.Radius*(1+sleepContactSlop)); ok {
+			unit.wake()
+		}
+	}
+}
+
+// The grid is rebuilt here, as units moved since the start of the step and
+// touching reaches further than an overlap.
+func (s *Simulation) updateSleep(dt float32) {
+	if dt <= 0 {
+		return
+	}
+
+	grid := s.newNeighbourGrid(2 * s.maxUnitRadius() * (1 + sleepContactSlop))
+
+	index := make(map[*Unit]int, len(s.Fluid))
+	for i, unit := range s.Fluid {
+		index[unit] = i
+	}
+
+	parent := make([]int, len(s.Fluid))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(a, b *Unit) {
+		if a.Asleep || b.Asleep {
+			return
+		}
+		parent[find(index[a])] = find(index[b])
+	}
+
+	for _, unit := range s.Fluid {
+		if unit == nil || unit.Asleep {
+			continue
+		}
+
+		velocity := unit.Velocity(dt)
+		acceleration := rl.Vector2Scale(rl.Vector2Subtract(velocity, unit.lastVelocity), 1/dt)
+		unit.lastVelocity = velocity
+
+		if rl.Vector2Length(velocity) < s.Config.SleepSpeed && rl.Vector2Length(acceleration) < s.Config.SleepAcceleration && unit != s.Interaction.Unit {
+			unit.restTime += dt
+		} else {
+			unit.restTime = 0
+		}
+
+		grid.forEachNear(unit.Position, func(other *Unit) {
+			if other != unit && touching(unit, other, s.separation(unit.Position, other.Position)) {
+				union(unit, other)
+			}
+		})
+		if unit.Body != nil {
+			union(unit, unit.Body.Units[0])
+		}
+	}
+
+	for _, constraint := range s.Constraints {
+		union(constraint.A, constraint.B)
+	}
+
+	rested := map[int]bool{}
+	members := map[int][]*Unit{}
+	for i, unit := range s.Fluid {
+		if unit == nil || unit.Asleep {
+			continue
+		}
+
+		root := find(i)
+		if _, seen := rested[root]; !seen {
+			rested[root] = true
+		}
+		rested[root] = rested[root] && unit.restTime >= s.Config.SleepDelay
+		members[root] = append(members[root], unit)
+	}
+
+	for root, units := range members {
+		if !rested[root] {
+			continue
+		}
+
+		sleeping := &island{units: units}
+		for _, unit := range units {
+			unit.Asleep = true
+			unit.island = sleeping
+			unit.PreviousPosition = unit.Position
+			unit.Acceleration = rl.Vector2{}
+			unit.AngularVelocity = 0
+			unit.lastVelocity = rl.Vector2{}
+			unit.restingBuoyancy = s.buoyancyAcceleration(unit)
+		}
+	}
+}
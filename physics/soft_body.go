@@ -0,0 +1,133 @@
+package physics
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Pressure is in N/m at zero area and grows with the relative loss of area.
+type SoftBody struct {
+	Units    []*Unit
+	RestArea float32
+	Pressure float32
+}
+
+func SoftBodyPositions(center rl.Vector2, radius float32, count int) []rl.Vector2 {
+	positions := make([]rl.Vector2, 0, count)
+	for i := 0; i < count; i++ {
+		angle := 2 * math.Pi * float64(i) / float64(count)
+		positions = append(positions, rl.Vector2{
+			X: center.X + radius*float32(math.Cos(angle)),
+			Y: center.Y + radius*float32(math.Sin(angle)),
+		})
+	}
+	return positions
+}
+
+func (s *Simulation) NewSoftBody(center rl.Vector2, radius float32) *SoftBody {
+	count := int(s.Config.SoftBodyParticles)
+	if count < 3 {
+		return nil
+	}
+
+	positions := SoftBodyPositions(center, radius, count)
+	maxUnitRadius := radius * float32(math.Sin(math.Pi/float64(count)))
+
+	body := &SoftBody{Pressure: s.Config.SoftBodyPressure}
+	for _, position := range positions {
+		unit := NewUnitWithProperties(s.Config)
+		unit.Radius = float32(math.Min(float64(unit.Radius), float64(maxUnitRadius)))
+		unit.Position = position
+		unit.PreviousPosition = position
+
+		body.Units = append(body.Units, unit)
+	}
+	s.Fluid = append(s.Fluid, body.Units...)
+
+	for i, unit := range body.Units {
+		edge := s.NewConstraint(unit, body.Units[(i+1)%count])
+		edge.Rigid = false
+	}
+
+	body.RestArea = s.SoftBodyArea(body)
+	s.SoftBodies = append(s.SoftBodies, body)
+
+	return body
+}
+
+// ring unwraps the units across periodic edges so the ring stays in one piece.
+func (s *Simulation) ring(b *SoftBody) []rl.Vector2 {
+	points := make([]rl.Vector2, 0, len(b.Units))
+	for i, unit := range b.Units {
+		if i == 0 {
+			points = append(points, unit.Position)
+			continue
+		}
+		previous := b.Units[i-1]
+		points = append(points, rl.Vector2Add(points[i-1], s.separation(previous.Position, unit.Position)))
+	}
+	return points
+}
+
+func signedArea(points []rl.Vector2) float32 {
+	area := float32(0)
+	for i, point := range points {
+		next := points[(i+1)%len(points)]
+		area += point.X*next.Y - next.X*point.Y
+	}
+	return area / 2
+}
+
+func (s *Simulation) SoftBodyArea(b *SoftBody) float32 {
+	return float32(math.Abs(float64(signedArea(s.ring(b)))))
+}
+
+func (s *Simulation) applyPressure(b *SoftBody) {
+	if b.RestArea == 0 {
+		return
+	}
+
+	points := s.ring(b)
+	area := signedArea(points)
+	orientation := float32(1)
+	if area < 0 {
+		orientation = -1
+	}
+
+	pressure := b.Pressure * (b.RestArea - orientation*area) / b.RestArea
+
+	for i, unit := range b.Units {
+		next := b.Units[(i+1)%len(b.Units)]
+		edge := rl.Vector2Subtract(points[(i+1)%len(points)], points[i])
+
+		// (dy, -dx) has the edge's length, so this is the force on the whole edge.
+		force := rl.Vector2Scale(rl.Vector2{X: edge.Y, Y: -edge.X}, orientation*pressure/2)
+
+		unit.accelerate(rl.Vector2Scale(force, 1/unit.Mass))
+		next.accelerate(rl.Vector2Scale(force, 1/next.Mass))
+	}
+}
+
+func (s *Simulation) applySoftBodyPressure() {
+	for _, body := range s.SoftBodies {
+		s.applyPressure(body)
+	}
+}
+
+func (s *Simulation) removeSoftBodiesOf(units map[*Unit]bool) {
+	remaining := s.SoftBodies[:0]
+	for _, body := range s.SoftBodies {
+		intact := true
+		for _, unit := range body.Units {
+			if units[unit] {
+				intact = false
+				break
+			}
+		}
+		if intact {
+			remaining = append(remaining, body)
+		}
+	}
+	s.SoftBodies = remaining
+}
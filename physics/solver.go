@@ -0,0 +1,229 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-fluid-simulator/config"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type solverPair struct {
+	a, b *Unit
+}
+
+type contactPair struct {
+	solverPair
+	normal               rl.Vector2
+	overlap              float32
+	velocityA, velocityB rl.Vector2
+}
+
+// Only the first iteration exchanges heat and applies restitution and friction.
+// Jacobi averages each unit's corrections, Gauss–Seidel applies them in turn.
+func (s *Simulation) solveOverlaps(grid *neighbourGrid) {
+	pairs := s.solverPairs(grid)
+
+	relaxation := s.Config.OverRelaxation
+	if relaxation <= 0 {
+		relaxation = 1
+	}
+
+	iterations := s.Config.SolverIterations
+	if iterations < 1 {
+		iterations = 1
+	}
+
+	for i := 0; i < iterations; i++ {
+		if s.Config.SolverOrdering == config.SolverJacobi {
+			s.relaxJacobi(pairs, relaxation, i == 0)
+		} else {
+			s.relaxGaussSeidel(pairs, relaxation, i == 0)
+		}
+	}
+
+	s.measureResidualOverlap(pairs)
+}
+
+func (s *Simulation) solverPairs(grid *neighbourGrid) []solverPair {
+	index := make(map[*Unit]int, len(s.Fluid))
+	for i, unit := range s.Fluid {
+		index[unit] = i
+	}
+
+	pairs := []solverPair{}
+	for _, unitA := range s.Fluid {
+		if unitA == nil {
+			continue
+		}
+
+		grid.forEachNear(unitA.Position, func(unitB *Unit) {
+			if index[unitB] <= index[unitA] || unitA.Body != nil && unitA.Body == unitB.Body {
+				return
+			}
+			pairs = append(pairs, solverPair{a: unitA, b: unitB})
+		})
+	}
+
+	return pairs
+}
+
+func (s *Simulation) relaxGaussSeidel(pairs []solverPair, relaxation float32, first bool) {
+	for _, pair := range pairs {
+		if pair.a.Asleep && pair.b.Asleep {
+			if first {
+				s.exchangeSleepingHeat(pair)
+			}
+			continue
+		}
+
+		delta := s.separation(pair.a.Position, pair.b.Position)
+		if !areOverlapping(pair.a, pair.b, delta) {
+			continue
+		}
+		pair.a.wake()
+		pair.b.wake()
+
+		if first {
+			calculateCollisionWithVerlet(pair.a, pair.b, delta, s.Config, s.Metrics.Timestep, relaxation)
+		} else {
+			projectOverlap(pair.a, pair.b, delta, relaxation)
+		}
+	}
+}
+
+func (s *Simulation) relaxJacobi(pairs []solverPair, relaxation float32, first bool) {
+	corrections := map[*Unit]rl.Vector2{}
+	counts := map[*Unit]int{}
+	contacts := []contactPair{}
+
+	for _, pair := range pairs {
+		if pair.a.Asleep && pair.b.Asleep {
+			if first {
+				s.exchangeSleepingHeat(pair)
+			}
+			continue
+		}
+
+		delta := s.separation(pair.a.Position, pair.b.Position)
+		distance := rl.Vector2Length(delta)
+		overlap := pair.a.Radius + pair.b.Radius - distance
+		if overlap <= 0 || distance == 0 {
+			continue
+		}
+		pair.a.wake()
+		pair.b.wake()
+
+		normal := rl.Vector2Scale(delta, 1/distance)
+		correction := rl.Vector2Scale(normal, overlap/2)
+		corrections[pair.a] = rl.Vector2Subtract(corrections[pair.a], correction)
+		corrections[pair.b] = rl.Vector2Add(corrections[pair.b], correction)
+		counts[pair.a]++
+		counts[pair.b]++
+
+		if first {
+			if s.Config.ApplyHeat {
+				exchangeHeat(pair.a, pair.b, overlap, s.Config, s.Metrics.Timestep)
+			}
+
+			contacts = append(contacts, contactPair{
+				solverPair: pair,
+				normal:     normal,
+				overlap:    overlap,
+				velocityA:  pair.a.GetVelocityWithVerlet(),
+				velocityB:  pair.b.GetVelocityWithVerlet(),
+			})
+		}
+	}
+
+	for unit, correction := range corrections {
+		unit.Position = rl.Vector2Add(unit.Position, rl.Vector2Scale(correction, relaxation/float32(counts[unit])))
+	}
+
+	s.applyJacobiRestitution(contacts)
+
+	for _, contact := range contacts {
+		applyContactFriction(contact.a, contact.b, contact.normal, contact.overlap, pairFriction(contact.a, contact.b, s.Config), s.Metrics.Timestep)
+	}
+}
+
+// A unit touching several others gets the mean of their restitution impulses
+// instead of only the last one.
+func (s *Simulation) applyJacobiRestitution(contacts []contactPair) {
+	velocities := map[*Unit]rl.Vector2{}
+	changes := map[*Unit]rl.Vector2{}
+	counts := map[*Unit]int{}
+
+	for _, contact := range contacts {
+		restitution, ok := pairRestitution(contact.a, contact.b, s.Config)
+		if !ok {
+			continue
+		}
+		velocities[contact.a] = contact.velocityA
+		velocities[contact.b] = contact.velocityB
+
+		approach := rl.Vector2DotProduct(rl.Vector2Subtract(contact.velocityB, contact.velocityA), contact.normal)
+		if approach >= 0 {
+			continue
+		}
+		impulse := restitutionImpulse(approach, contact.a.Mass, contact.b.Mass, restitution)
+		changes[contact.a] = rl.Vector2Subtract(changes[contact.a], rl.Vector2Scale(contact.normal, impulse/contact.a.Mass))
+		changes[contact.b] = rl.Vector2Add(changes[contact.b], rl.Vector2Scale(contact.normal, impulse/contact.b.Mass))
+		counts[contact.a]++
+		counts[contact.b]++
+	}
+
+	for unit, velocity := range velocities {
+		if counts[unit] > 0 {
+			velocity = rl.Vector2Add(velocity, rl.Vector2Scale(changes[unit], 1/float32(counts[unit])))
+		}
+		unit.PreviousPosition = rl.Vector2Subtract(unit.Position, velocity)
+	}
+}
+
+// A settled layer keeps warming up until buoyancy wakes it.
+func (s *Simulation) exchangeSleepingHeat(pair solverPair) {
+	if !s.Config.ApplyHeat {
+		return
+	}
+
+	delta := s.separation(pair.a.Position, pair.b.Position)
+	if overlap := pair.a.Radius + pair.b.Radius - rl.Vector2Length(delta); overlap > 0 {
+		exchangeHeat(pair.a, pair.b, overlap, s.Config, s.Metrics.Timestep)
+	}
+}
+
+func projectOverlap(a, b *Unit, delta rl.Vector2, relaxation float32) {
+	distance := rl.Vector2Length(delta)
+	overlap := a.Radius + b.Radius - distance
+	if overlap <= 0 || distance == 0 {
+		return
+	}
+
+	correction := rl.Vector2Scale(delta, overlap/2*relaxation/distance)
+	a.Position = rl.Vector2Subtract(a.Position, correction)
+	b.Position = rl.Vector2Add(b.Position, correction)
+}
+
+func (s *Simulation) measureResidualOverlap(pairs []solverPair) {
+	largest := float32(0)
+	total := float32(0)
+	overlapping := 0
+
+	for _, pair := range pairs {
+		distance := rl.Vector2Length(s.separation(pair.a.Position, pair.b.Position))
+		overlap := pair.a.Radius + pair.b.Radius - distance
+		if overlap <= 0 {
+			continue
+		}
+
+		largest = float32(math.Max(float64(largest), float64(overlap)))
+		total += overlap
+		overlapping++
+	}
+
+	s.Metrics.MaxResidualOverlap = largest
+	s.Metrics.MeanResidualOverlap = 0
+	if overlapping > 0 {
+		s.Metrics.MeanResidualOverlap = total / float32(overlapping)
+	}
+}
@@ -0,0 +1,110 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-fluid-simulator/config"
+	"github.com/alexanderi96/go-fluid-simulator/utils"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// spawnSpacing leaves ParticleInitialSpacing between the largest units that
+// can be generated.
+func spawnSpacing(cfg *config.Config) float32 {
+	return 2*SpawnRadius(cfg) + cfg.ParticleInitialSpacing
+}
+
+func SpawnRadius(cfg *config.Config) float32 {
+	if material := cfg.Material(cfg.ActiveMaterial); material != nil {
+		return material.Radius
+	}
+	if cfg.SetRandomRadius {
+		return cfg.RadiusMax
+	}
+	return cfg.ParticleRadius
+}
+
+func gridPositions(bounds rl.Rectangle, spacing float32, inside func(rl.Vector2) bool) []rl.Vector2 {
+	if spacing <= 0 || bounds.Width < 0 || bounds.Height < 0 {
+		return nil
+	}
+
+	columns := int(bounds.Width/spacing) + 1
+	rows := int(bounds.Height/spacing) + 1
+
+	offsetX := bounds.X + (bounds.Width-float32(columns-1)*spacing)/2
+	offsetY := bounds.Y + (bounds.Height-float32(rows-1)*spacing)/2
+
+	positions := make([]rl.Vector2, 0, columns*rows)
+	for row := 0; row < rows; row++ {
+		for column := 0; column < columns; column++ {
+			position := rl.Vector2{
+				X: offsetX + float32(column)*spacing,
+				Y: offsetY + float32(row)*spacing,
+			}
+
+			if inside(position) {
+				positions = append(positions, position)
+			}
+		}
+	}
+
+	return positions
+}
+
+func RectangleSpawnPositions(rect rl.Rectangle, cfg *config.Config) []rl.Vector2 {
+	return gridPositions(rect, spawnSpacing(cfg), func(rl.Vector2) bool { return true })
+}
+
+func CircleSpawnPositions(center rl.Vector2, radius float32, cfg *config.Config) []rl.Vector2 {
+	bounds := rl.Rectangle{X: center.X - radius, Y: center.Y - radius, Width: 2 * radius, Height: 2 * radius}
+
+	return gridPositions(bounds, spawnSpacing(cfg), func(p rl.Vector2) bool {
+		return distanceBetween(p, center) <= radius
+	})
+}
+
+func PolygonSpawnPositions(points []rl.Vector2, cfg *config.Config) []rl.Vector2 {
+	if len(points) < 3 {
+		return nil
+	}
+
+	return gridPositions(utils.PolygonBounds(points), spawnSpacing(cfg), func(p rl.Vector2) bool {
+		return utils.PointInPolygon(p, points)
+	})
+}
+
+func LineSpawnPositions(start, end rl.Vector2, cfg *config.Config) []rl.Vector2 {
+	spacing := spawnSpacing(cfg)
+	if spacing <= 0 {
+		return nil
+	}
+
+	length := distanceBetween(start, end)
+	count := int(math.Floor(float64(length/spacing))) + 1
+
+	positions := make([]rl.Vector2, 0, count)
+	for i := 0; i < count; i++ {
+		t := float32(0)
+		if length > 0 {
+			t = float32(i) * spacing / length
+		}
+		positions = append(positions, rl.Vector2Lerp(start, end, t))
+	}
+
+	return positions
+}
+
+func (s *Simulation) NewFluidAtPositions(positions []rl.Vector2) {
+	units := make([]*Unit, 0, len(positions))
+
+	for _, position := range positions {
+		unit := NewUnitWithProperties(s.Config)
+		unit.Position = position
+		unit.PreviousPosition = position
+
+		units = append(units, unit)
+	}
+
+	s.Fluid = append(s.Fluid, units...)
+}
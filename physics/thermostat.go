@@ -0,0 +1,50 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-fluid-simulator/config"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// KineticTemperature is kT/ε: with two degrees of freedom, the mean kinetic
+// energy per unit over ε.
+func (s *Simulation) KineticTemperature() float32 {
+	if len(s.Fluid) == 0 || s.Config.LJEpsilon <= 0 {
+		return 0
+	}
+
+	energy := float32(0)
+	for _, unit := range s.Fluid {
+		energy += unit.KineticEnergy(s.Metrics.Timestep)
+	}
+	return energy / float32(len(s.Fluid)) / s.Config.LJEpsilon
+}
+
+// Berendsen closes the gap to TargetTemperature over BerendsenTau seconds
+// instead of in one step.
+func (s *Simulation) applyThermostat(dt float32) {
+	current := s.KineticTemperature()
+	target := s.Config.TargetTemperature
+	if current <= 0 || target < 0 {
+		return
+	}
+
+	var scale float32
+	switch s.Config.Thermostat {
+	case config.ThermostatRescale:
+		scale = float32(math.Sqrt(float64(target / current)))
+	case config.ThermostatBerendsen:
+		if s.Config.BerendsenTau <= 0 {
+			return
+		}
+		scale = float32(math.Sqrt(math.Max(0, float64(1+dt/s.Config.BerendsenTau*(target/current-1)))))
+	default:
+		return
+	}
+
+	for _, unit := range s.Fluid {
+		velocity := rl.Vector2Scale(unit.GetVelocityWithVerlet(), scale)
+		unit.PreviousPosition = rl.Vector2Subtract(unit.Position, velocity)
+	}
+}
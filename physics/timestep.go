@@ -0,0 +1,83 @@
+package physics
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Past MaxSubsteps the simulation falls behind real time rather than take
+// longer steps.
+func (s *Simulation) chooseTimestep(frame float32) (float32, int) {
+	if !s.Config.AdaptiveTimestep || frame <= 0 {
+		return frame, 1
+	}
+
+	dt := s.Config.MaxTimestep
+	if speed := s.maxUnitSpeed(); speed > 0 {
+		dt = s.Config.CourantNumber * s.minUnitRadius() / speed
+	}
+	dt = float32(math.Min(math.Max(float64(dt), float64(s.Config.MinTimestep)), float64(s.Config.MaxTimestep)))
+	if dt <= 0 {
+		return frame, 1
+	}
+
+	substeps := int(math.Ceil(float64(frame / dt)))
+	if substeps > s.Config.MaxSubsteps && s.Config.MaxSubsteps > 0 {
+		return dt, s.Config.MaxSubsteps
+	}
+	return frame / float32(substeps), substeps
+}
+
+// Velocities are stored as the displacement over one step, so they are rescaled
+// to keep their value in m/s.
+func (s *Simulation) setTimestep(dt float32) {
+	previous := s.Metrics.Timestep
+	s.Metrics.Timestep = dt
+
+	if previous <= 0 || previous == dt {
+		return
+	}
+
+	scale := dt / previous
+	for _, unit := range s.Fluid {
+		if unit != nil {
+			velocity := rl.Vector2Scale(unit.GetVelocityWithVerlet(), scale)
+			unit.PreviousPosition = rl.Vector2Subtract(unit.Position, velocity)
+		}
+	}
+	for _, unit := range s.Fluid3D {
+		velocity := rl.Vector3Scale(unit.GetVelocityWithVerlet(), scale)
+		unit.PreviousPosition = rl.Vector3Subtract(unit.Position, velocity)
+	}
+}
+
+func (s *Simulation) maxUnitSpeed() float32 {
+	speed := float32(0)
+	for _, unit := range s.Fluid {
+		if unit != nil {
+			speed = float32(math.Max(float64(speed), float64(rl.Vector2Length(unit.Velocity(s.Metrics.Timestep)))))
+		}
+	}
+	if s.Metrics.Timestep > 0 {
+		for _, unit := range s.Fluid3D {
+			speed = float32(math.Max(float64(speed), float64(rl.Vector3Length(unit.GetVelocityWithVerlet())/s.Metrics.Timestep)))
+		}
+	}
+	return speed
+}
+
+func (s *Simulation) minUnitRadius() float32 {
+	radius := float32(math.MaxFloat32)
+	for _, unit := range s.Fluid {
+		if unit != nil && unit.Radius < radius {
+			radius = unit.Radius
+		}
+	}
+	for _, unit := range s.Fluid3D {
+		if unit.Radius < radius {
+			radius = unit.Radius
+		}
+	}
+	return radius
+}
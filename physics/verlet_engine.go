@@ -1,32 +1,115 @@
 package physics
 
 import (
+	"math"
+
+	"github.com/alexanderi96/go-fluid-simulator/config"
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 func (s *Simulation) UpdateWithVerletIntegration() error {
-	for _, unitA := range s.Fluid {
-		if unitA == nil {
-			continue
-		}
+	integrator, err := NewIntegrator(s.Config.Integrator)
+	if err != nil {
+		return err
+	}
+
+	s.Time += s.Metrics.Timestep
+
+	for _, obstacle := range s.Obstacles {
+		obstacle.update(s.Time)
+	}
+
+	lennardJones := s.Config.InteractionMode == config.InteractionLennardJones
+
+	reach := 2 * s.maxUnitRadius()
+	if s.Config.ApplyCohesion {
+		reach += s.Config.CohesionRange
+	}
+	if lennardJones {
+		reach = float32(math.Max(float64(reach), float64(s.lennardJonesReach())))
+	}
+	grid := s.newNeighbourGrid(reach)
+
+	sleeping := s.Config.ApplySleep && !lennardJones
+	if sleeping {
+		s.wakeDisturbed(grid)
+	} else {
+		s.WakeAll()
+	}
+
+	if lennardJones {
+		s.applyLennardJones(grid)
+	} else {
+		s.solveOverlaps(grid)
+	}
+
+	if s.Config.ApplyCohesion {
+		s.applyCohesion(grid)
+	}
+
+	if s.Config.ApplySelfGravity {
+		s.applySelfGravity()
+	}
+
+	s.applySpringForces(s.Metrics.Timestep)
+	s.applySoftBodyPressure()
 
-		for _, unitB := range s.Fluid {
-			if unitB == nil {
-				continue
-			}
+	var starts []rl.Vector2
+	if s.Config.ApplyCCD {
+		starts = make([]rl.Vector2, len(s.Fluid))
+	}
 
-			if unitA.Id != unitB.Id && areOverlapping(unitA, unitB) {
-				calculateCollisionWithVerlet(unitA, unitB)
-			}
+	for i, unit := range s.Fluid {
+		if starts != nil {
+			starts[i] = unit.Position
+		}
+		if unit.Asleep {
+			unit.Acceleration = rl.Vector2{}
+			continue
 		}
+		s.integrate(unit, integrator, s.Metrics.Timestep)
+		unit.applyDrag(s.Config, s.Metrics.Timestep)
+	}
+
+	if starts != nil {
+		s.sweepUnits(starts)
 	}
 
 	for _, unit := range s.Fluid {
-		if s.Config.ApplyGravity {
-			unit.accelerate(rl.Vector2{X: 0, Y: s.Config.Gravity})
+		if !unit.Asleep {
+			s.applyContainer(unit)
+		}
+
+		if s.Config.ApplyHeat {
+			s.exchangeWallHeat(unit, s.Metrics.Timestep)
+		}
+		if unit.Asleep {
+			continue
+		}
+
+		for _, obstacle := range s.Obstacles {
+			unit.checkObstacleCollisionVerlet(obstacle, s.Config, s.Metrics.Timestep)
 		}
-		unit.updatePositionWithVerlet(s.Metrics.Frametime)
-		unit.checkWallCollisionVerlet(s.Config, s.Metrics.Frametime)
+	}
+
+	if s.Config.ApplyXSPH {
+		s.smoothVelocities()
+	}
+
+	s.applyThermostat(s.Metrics.Timestep)
+
+	s.solveRigidConstraints()
+	s.breakConstraints()
+	s.matchRigidBodies(s.Metrics.Timestep)
+
+	s.removeEscapedUnits()
+
+	if sleeping {
+		s.updateSleep(s.Metrics.Timestep)
+	}
+
+	if s.Config.ApplySelfGravity {
+		s.updateEnergyDiagnostics()
 	}
 
 	return nil
@@ -0,0 +1,49 @@
+package physics
+
+import (
+	"math/rand"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const spawnSpeed3D = 5.0
+
+func (s *Simulation) NewFluid3D() {
+	s.Fluid3D = append(s.Fluid3D, newUnits3DInBlock(s.Config, int(s.Config.ParticleNumber), rl.Vector3{}, s.Metrics.Timestep)...)
+}
+
+func (s *Simulation) NewFluid3DWithVelocity() {
+	direction := rl.Vector3Normalize(rl.Vector3{
+		X: rand.Float32()*2 - 1,
+		Y: -rand.Float32(),
+		Z: rand.Float32()*2 - 1,
+	})
+	velocity := rl.Vector3Scale(direction, spawnSpeed3D)
+
+	s.Fluid3D = append(s.Fluid3D, newUnits3DInBlock(s.Config, int(s.Config.ParticleNumber), velocity, s.Metrics.Timestep)...)
+}
+
+// Obstacles, containers, constraints, heat, friction, cohesion, force fields,
+// integrators, CCD and sleeping are 2D only.
+func (s *Simulation) UpdateWithVerletIntegration3D() error {
+	grid := newNeighbourGrid3D(s.Fluid3D, 2*s.maxUnitRadius3D())
+
+	for i, unitA := range s.Fluid3D {
+		grid.forEachNear(unitA.Position, func(j int) {
+			unitB := s.Fluid3D[j]
+			if j > i && areOverlapping3D(unitA, unitB) {
+				calculateCollisionWithVerlet3D(unitA, unitB)
+			}
+		})
+	}
+
+	for _, unit := range s.Fluid3D {
+		if s.Config.ApplyGravity {
+			unit.accelerate(s.Config.Gravity3D())
+		}
+		unit.updatePositionWithVerlet(s.Metrics.Timestep)
+		unit.checkWallCollisionVerlet(s.Config)
+	}
+
+	return nil
+}
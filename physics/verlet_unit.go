@@ -17,13 +17,28 @@ type Unit struct {
 	Position         rl.Vector2
 	PreviousPosition rl.Vector2
 	Acceleration     rl.Vector2
+	Orientation      float32
+	AngularVelocity  float32
 	Elasticity       float32
 	Radius           float32
 	Mass             float32
+	Temperature      float32
 	Color            color.RGBA
+	Material         *config.Material
+	Body             *RigidBody
+	Asleep           bool
+
+	restTime        float32
+	lastVelocity    rl.Vector2
+	restingBuoyancy rl.Vector2
+	island          *island
 }
 
-func NewUnitWithProperties(cfg *config.Config) *Unit {
+func unitProperties(cfg *config.Config) (float32, float32, float32, color.RGBA) {
+	if material := cfg.Material(cfg.ActiveMaterial); material != nil {
+		return material.Radius, material.Mass, material.Elasticity, material.RGBA
+	}
+
 	currentRadius := cfg.ParticleRadius
 	currentMass := cfg.ParticleMass
 	currentElasticity := cfg.ParticleElasticity
@@ -44,12 +59,20 @@ func NewUnitWithProperties(cfg *config.Config) *Unit {
 		color = utils.RandomRaylibColor()
 	}
 
+	return currentRadius, currentMass, currentElasticity, color
+}
+
+func NewUnitWithProperties(cfg *config.Config) *Unit {
+	radius, mass, elasticity, color := unitProperties(cfg)
+
 	return &Unit{
-		Id:         uuid.New(),
-		Radius:     currentRadius,
-		Mass:       currentMass,
-		Elasticity: currentElasticity,
-		Color:      color,
+		Id:          uuid.New(),
+		Radius:      radius,
+		Mass:        mass,
+		Elasticity:  elasticity,
+		Color:       color,
+		Material:    cfg.Material(cfg.ActiveMaterial),
+		Temperature: cfg.AmbientTemperature,
 	}
 }
 
@@ -109,43 +132,39 @@ func newUnitsAtPosition(spawnPosition rl.Vector2, cfg *config.Config) *[]*Unit {
 	return &units
 }
 
-func calculateInitialVelocity(position rl.Vector2, simulationWidth, simulationHeight int32) rl.Vector2 {
-	const maxSpeed float32 = 1000.0
-
+func calculateInitialVelocity(position rl.Vector2, simulationWidth, simulationHeight, maxSpeed float32) rl.Vector2 {
 	d_left := position.X
-	d_right := float32(simulationWidth) - position.X
+	d_right := simulationWidth - position.X
 	d_top := position.Y
-	d_bottom := float32(simulationHeight) - position.Y
+	d_bottom := simulationHeight - position.Y
 
 	var velocityX, velocityY float32
 
-	velocityX = maxSpeed*(d_right/float32(simulationWidth)) - maxSpeed*(d_left/float32(simulationWidth))
-	velocityY = maxSpeed*(d_bottom/float32(simulationHeight)) - maxSpeed*(d_top/float32(simulationHeight))
+	velocityX = maxSpeed*(d_right/simulationWidth) - maxSpeed*(d_left/simulationWidth)
+	velocityY = maxSpeed*(d_bottom/simulationHeight) - maxSpeed*(d_top/simulationHeight)
 
 	return rl.Vector2{X: velocityX, Y: velocityY}
 }
-func spawnUnitsWithVelocity(units *[]*Unit, spawnPosition rl.Vector2, cfg *config.Config) {
-	var lastSpawned *Unit = nil
-
-	for i := 0; i < int(cfg.ParticleNumber); i++ {
-		unit := *NewUnitWithProperties(cfg)
 
-		for lastSpawned != nil && distanceBetween(lastSpawned.Position, spawnPosition) < 2*unit.Radius {
-			time.Sleep(100 * time.Millisecond)
-		}
+type spawnedUnit struct {
+	unit     *Unit
+	velocity rl.Vector2
+}
 
-		velocity := calculateInitialVelocity(spawnPosition, cfg.GameX, cfg.GameY)
+// Each unit waits until the previous one has moved out of the spawn position.
+func spawnUnitsWithVelocity(spawned chan<- spawnedUnit, units []*Unit, velocity rl.Vector2) {
+	speed := rl.Vector2Length(velocity)
 
-		previousPosition := rl.Vector2{
-			X: spawnPosition.X - velocity.X/float32(rl.GetFPS()),
-			Y: spawnPosition.Y - velocity.Y/float32(rl.GetFPS()),
+	for i, unit := range units {
+		if i > 0 {
+			wait := 100 * time.Millisecond
+			if speed > 0 {
+				wait = time.Duration(float64(2*units[i-1].Radius/speed) * float64(time.Second))
+			}
+			time.Sleep(wait)
 		}
 
-		unit.Position = spawnPosition
-		unit.PreviousPosition = previousPosition
-
-		*units = append(*units, &unit)
-		lastSpawned = &unit
+		spawned <- spawnedUnit{unit: unit, velocity: velocity}
 	}
 }
 
@@ -167,17 +186,15 @@ func (u *Unit) accelerate(a rl.Vector2) {
 	u.Acceleration.Y += a.Y
 }
 
-func areOverlapping(a, b *Unit) bool {
-	deltaX := b.Position.X - a.Position.X
-	deltaY := b.Position.Y - a.Position.Y
-	distanceSquared := deltaX*deltaX + deltaY*deltaY
+func areOverlapping(a, b *Unit, delta rl.Vector2) bool {
+	distanceSquared := delta.X*delta.X + delta.Y*delta.Y
 	totalRadius := a.Radius + b.Radius
 	return distanceSquared < totalRadius*totalRadius
 }
-func calculateCollisionWithVerlet(unitA, unitB *Unit) {
+func calculateCollisionWithVerlet(unitA, unitB *Unit, delta rl.Vector2, cfg *config.Config, dt, relaxation float32) {
 
-	deltaX := unitB.Position.X - unitA.Position.X
-	deltaY := unitB.Position.Y - unitA.Position.Y
+	deltaX := delta.X
+	deltaY := delta.Y
 
 	distance := float32(math.Sqrt(float64(deltaX*deltaX + deltaY*deltaY)))
 	overlap := unitA.Radius + unitB.Radius - distance
@@ -189,14 +206,27 @@ func calculateCollisionWithVerlet(unitA, unitB *Unit) {
 	normalX := deltaX / distance
 	normalY := deltaY / distance
 
-	correctionX := (overlap / 2) * normalX
-	correctionY := (overlap / 2) * normalY
+	if cfg.ApplyHeat {
+		exchangeHeat(unitA, unitB, overlap, cfg, dt)
+	}
+
+	velocityA := unitA.GetVelocityWithVerlet()
+	velocityB := unitB.GetVelocityWithVerlet()
+
+	correctionX := (overlap / 2) * relaxation * normalX
+	correctionY := (overlap / 2) * relaxation * normalY
 
 	unitA.Position.X -= correctionX
 	unitA.Position.Y -= correctionY
 	unitB.Position.X += correctionX
 	unitB.Position.Y += correctionY
 
+	normal := rl.Vector2{X: normalX, Y: normalY}
+	if restitution, ok := pairRestitution(unitA, unitB, cfg); ok {
+		applyRestitution(unitA, unitB, normal, velocityA, velocityB, restitution)
+	}
+
+	applyContactFriction(unitA, unitB, normal, overlap, pairFriction(unitA, unitB, cfg), dt)
 }
 
 func (u *Unit) updatePositionWithVerlet(dt float32) {
@@ -207,30 +237,39 @@ func (u *Unit) updatePositionWithVerlet(dt float32) {
 	u.PreviousPosition = u.Position
 	u.Position = newPosition
 	u.Acceleration = rl.Vector2{X: 0, Y: 0}
+	u.Orientation += u.AngularVelocity * dt
 }
 
 func (u *Unit) checkWallCollisionVerlet(cfg *config.Config, deltaTime float32) {
+	if !cfg.PeriodicX {
+		correction := bounceAxis(&u.Position.X, &u.PreviousPosition.X, u.Radius, cfg.GameX, cfg.WallElasticity, false, false)
+		if correction != 0 {
+			normal := rl.Vector2{X: float32(math.Copysign(1, float64(correction)))}
+			u.applyWallFriction(normal, rl.Vector2{}, float32(math.Abs(float64(correction))), cfg.WallFriction, deltaTime)
+		}
+	}
 
-	position := u.Position
-
-	if u.Position.X-u.Radius < 0 {
-		u.Position.X = u.Radius
-
-		u.PreviousPosition.X = u.Position.X + (u.Position.X-position.X)*-cfg.WallElasticity
-	} else if u.Position.X+u.Radius > float32(cfg.GameX) {
-		u.Position.X = float32(cfg.GameX) - u.Radius
-
-		u.PreviousPosition.X = u.Position.X + (u.Position.X-position.X)*-cfg.WallElasticity
+	if !cfg.PeriodicY {
+		correction := bounceAxis(&u.Position.Y, &u.PreviousPosition.Y, u.Radius, cfg.GameY, cfg.WallElasticity, cfg.OpenTop, cfg.OpenFloor)
+		if correction != 0 {
+			normal := rl.Vector2{Y: float32(math.Copysign(1, float64(correction)))}
+			u.applyWallFriction(normal, rl.Vector2{}, float32(math.Abs(float64(correction))), cfg.WallFriction, deltaTime)
+		}
 	}
+}
+
+func bounceAxis(position, previous *float32, radius, size, elasticity float32, openMin, openMax bool) float32 {
+	before := *position
 
-	if u.Position.Y-u.Radius < 0 {
-		u.Position.Y = u.Radius
+	if *position-radius < 0 && !openMin {
+		*position = radius
 
-		u.PreviousPosition.Y = u.Position.Y + (u.Position.Y-position.Y)*-cfg.WallElasticity
-	} else if u.Position.Y+u.Radius > float32(cfg.GameY) {
-		u.Position.Y = float32(cfg.GameY) - u.Radius
+		*previous = *position + (*position-before)*-elasticity
+	} else if *position+radius > size && !openMax {
+		*position = size - radius
 
-		u.PreviousPosition.Y = u.Position.Y + (u.Position.Y-position.Y)*-cfg.WallElasticity
+		*previous = *position + (*position-before)*-elasticity
 	}
 
+	return *position - before
 }
@@ -0,0 +1,128 @@
+package physics
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/alexanderi96/go-fluid-simulator/config"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/google/uuid"
+)
+
+// The 3D box is [0,GameX]×[0,GameY]×[0,GameZ] with Y pointing up.
+type Unit3D struct {
+	Id               uuid.UUID
+	Position         rl.Vector3
+	PreviousPosition rl.Vector3
+	Acceleration     rl.Vector3
+	Elasticity       float32
+	Radius           float32
+	Mass             float32
+	Color            color.RGBA
+}
+
+func NewUnit3DWithProperties(cfg *config.Config) *Unit3D {
+	radius, mass, elasticity, color := unitProperties(cfg)
+
+	return &Unit3D{
+		Id:         uuid.New(),
+		Radius:     radius,
+		Mass:       mass,
+		Elasticity: elasticity,
+		Color:      color,
+	}
+}
+
+func newUnits3DInBlock(cfg *config.Config, count int, velocity rl.Vector3, dt float32) []*Unit3D {
+	units := make([]*Unit3D, 0, count)
+
+	spacing := spawnSpacing(cfg)
+	side := int(math.Ceil(math.Cbrt(float64(count))))
+	extent := float32(side-1) * spacing
+
+	origin := rl.Vector3{
+		X: cfg.GameX/2 - extent/2,
+		Y: cfg.GameY - extent - spacing,
+		Z: cfg.GameZ/2 - extent/2,
+	}
+
+	for i := 0; i < count; i++ {
+		unit := NewUnit3DWithProperties(cfg)
+
+		// A small jitter keeps perfectly stacked columns from staying balanced.
+		jitter := spacing * 0.01
+		unit.Position = rl.Vector3{
+			X: origin.X + float32(i%side)*spacing + (rand.Float32()-0.5)*jitter,
+			Y: origin.Y + float32(i/(side*side))*spacing,
+			Z: origin.Z + float32((i/side)%side)*spacing + (rand.Float32()-0.5)*jitter,
+		}
+		unit.PreviousPosition = rl.Vector3Subtract(unit.Position, rl.Vector3Scale(velocity, dt))
+
+		units = append(units, unit)
+	}
+
+	return units
+}
+
+func (u *Unit3D) GetVelocityWithVerlet() rl.Vector3 {
+	return rl.Vector3Subtract(u.Position, u.PreviousPosition)
+}
+
+func (u *Unit3D) accelerate(a rl.Vector3) {
+	u.Acceleration = rl.Vector3Add(u.Acceleration, a)
+}
+
+func areOverlapping3D(a, b *Unit3D) bool {
+	delta := rl.Vector3Subtract(b.Position, a.Position)
+	distanceSquared := rl.Vector3DotProduct(delta, delta)
+	totalRadius := a.Radius + b.Radius
+	return distanceSquared < totalRadius*totalRadius
+}
+
+func calculateCollisionWithVerlet3D(unitA, unitB *Unit3D) {
+	delta := rl.Vector3Subtract(unitB.Position, unitA.Position)
+
+	distance := rl.Vector3Length(delta)
+	overlap := unitA.Radius + unitB.Radius - distance
+
+	if overlap <= 0 || distance == 0 {
+		return
+	}
+
+	normal := rl.Vector3Scale(delta, 1/distance)
+	velocityA := unitA.GetVelocityWithVerlet()
+	velocityB := unitB.GetVelocityWithVerlet()
+
+	correction := rl.Vector3Scale(normal, overlap/2)
+	unitA.Position = rl.Vector3Subtract(unitA.Position, correction)
+	unitB.Position = rl.Vector3Add(unitB.Position, correction)
+
+	approach := rl.Vector3DotProduct(rl.Vector3Subtract(velocityB, velocityA), normal)
+	if approach < 0 {
+		impulse := restitutionImpulse(approach, unitA.Mass, unitB.Mass, (unitA.Elasticity+unitB.Elasticity)/2)
+		velocityA = rl.Vector3Subtract(velocityA, rl.Vector3Scale(normal, impulse/unitA.Mass))
+		velocityB = rl.Vector3Add(velocityB, rl.Vector3Scale(normal, impulse/unitB.Mass))
+	}
+
+	unitA.PreviousPosition = rl.Vector3Subtract(unitA.Position, velocityA)
+	unitB.PreviousPosition = rl.Vector3Subtract(unitB.Position, velocityB)
+}
+
+func (u *Unit3D) updatePositionWithVerlet(dt float32) {
+	newPosition := rl.Vector3{
+		X: 2*u.Position.X - u.PreviousPosition.X + u.Acceleration.X*dt*dt,
+		Y: 2*u.Position.Y - u.PreviousPosition.Y + u.Acceleration.Y*dt*dt,
+		Z: 2*u.Position.Z - u.PreviousPosition.Z + u.Acceleration.Z*dt*dt,
+	}
+
+	u.PreviousPosition = u.Position
+	u.Position = newPosition
+	u.Acceleration = rl.Vector3{}
+}
+
+func (u *Unit3D) checkWallCollisionVerlet(cfg *config.Config) {
+	bounceAxis(&u.Position.X, &u.PreviousPosition.X, u.Radius, cfg.GameX, cfg.WallElasticity, false, false)
+	bounceAxis(&u.Position.Y, &u.PreviousPosition.Y, u.Radius, cfg.GameY, cfg.WallElasticity, false, false)
+	bounceAxis(&u.Position.Z, &u.PreviousPosition.Z, u.Radius, cfg.GameZ, cfg.WallElasticity, false, false)
+}
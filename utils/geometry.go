@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// PointInPolygon uses the even-odd rule, so concave polygons work too.
+func PointInPolygon(point rl.Vector2, points []rl.Vector2) bool {
+	inside := false
+
+	for i, j := 0, len(points)-1; i < len(points); j, i = i, i+1 {
+		a := points[i]
+		b := points[j]
+
+		if (a.Y > point.Y) != (b.Y > point.Y) &&
+			point.X < (b.X-a.X)*(point.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+func PolygonBounds(points []rl.Vector2) rl.Rectangle {
+	if len(points) == 0 {
+		return rl.Rectangle{}
+	}
+
+	minX, minY := points[0].X, points[0].Y
+	maxX, maxY := points[0].X, points[0].Y
+
+	for _, p := range points[1:] {
+		minX = float32(math.Min(float64(minX), float64(p.X)))
+		minY = float32(math.Min(float64(minY), float64(p.Y)))
+		maxX = float32(math.Max(float64(maxX), float64(p.X)))
+		maxY = float32(math.Max(float64(maxY), float64(p.Y)))
+	}
+
+	return rl.Rectangle{X: minX, Y: minY, Width: maxX - minX, Height: maxY - minY}
+}
+
+func RectangleFromCorners(a, b rl.Vector2) rl.Rectangle {
+	return PolygonBounds([]rl.Vector2{a, b})
+}
@@ -6,6 +6,7 @@ import (
 	"math"
 	"math/rand"
 	"strconv"
+	"strings"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
@@ -23,10 +24,10 @@ func RandomRaylibColor() rl.Color {
 	return rl.NewColor(r, g, b, a)
 }
 
-func GetColorFromVelocity(v rl.Vector2) color.RGBA {
+func GetColorFromVelocity(v rl.Vector2, maxSpeed float32) color.RGBA {
 
 	magnitude := math.Sqrt(float64(v.X*v.X + v.Y*v.Y))
-	colorFactor := math.Min(1, math.Pow(magnitude, 0.5))
+	colorFactor := math.Min(1, math.Pow(magnitude/float64(maxSpeed), 0.5))
 
 	R := uint8(255 * colorFactor)
 	G := uint8(0)
@@ -40,6 +41,21 @@ func GetColorFromVelocity(v rl.Vector2) color.RGBA {
 	}
 }
 
+func GetColorFromTemperature(temperature, cold, hot float32) color.RGBA {
+	factor := 0.5
+	if hot > cold {
+		factor = math.Max(0, math.Min(1, float64((temperature-cold)/(hot-cold))))
+	}
+
+	if factor < 0.5 {
+		shade := uint8(255 * factor * 2)
+		return color.RGBA{R: shade, G: shade, B: 255, A: 255}
+	}
+
+	shade := uint8(255 * (1 - factor) * 2)
+	return color.RGBA{R: 255, G: shade, B: shade, A: 255}
+}
+
 func CheckTextFloat32(radMinText string) (float32, error) {
 	floatValue, err := strconv.ParseFloat(radMinText, 32)
 	if err == nil {
@@ -48,3 +64,21 @@ func CheckTextFloat32(radMinText string) (float32, error) {
 		return 0, fmt.Errorf("error parsing float value: %v", err)
 	}
 }
+
+// ParseHexColor parses a colour written as #RRGGBB or #RRGGBBAA.
+func ParseHexColor(text string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(text, "#")
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return color.RGBA{}, fmt.Errorf("invalid colour %q", text)
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid colour %q: %v", text, err)
+	}
+
+	return color.RGBA{R: uint8(value >> 24), G: uint8(value >> 16), B: uint8(value >> 8), A: uint8(value)}, nil
+}